@@ -0,0 +1,101 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+// Functions exported to C are defined in this file, so its preamble may only contain declarations.
+// The C functions that call them are defined in the preambles of the files that use them.
+
+// #cgo linux pkg-config: redland raptor2
+// #cgo LDFLAGS: -lrdf
+// #include <stdint.h>
+// #include <stdlib.h>
+// #include <librdf.h>
+import "C"
+
+import (
+	"io"
+	"runtime/cgo"
+	"unsafe"
+)
+
+//iostreamWriter is the Go side of a raptor iostream that writes to an io.Writer
+//	The first error returned by the writer is kept so that it can be reported once serialization ends
+type iostreamWriter struct {
+	w   io.Writer
+	err error
+}
+
+//golibrdfIostreamWriteBytes is called by raptor to write nmemb objects of size bytes to the writer held by handle
+//	The number of objects written is returned, which is less than nmemb if the writer failed
+//
+//export golibrdfIostreamWriteBytes
+func golibrdfIostreamWriteBytes(handle C.uintptr_t, ptr unsafe.Pointer, size C.size_t, nmemb C.size_t) C.int {
+	writer := cgo.Handle(handle).Value().(*iostreamWriter)
+
+	if writer.err != nil {
+		return 0
+	}
+
+	length := int(size) * int(nmemb)
+	if length == 0 {
+		return C.int(nmemb)
+	}
+
+	n, err := writer.w.Write(unsafe.Slice((*byte)(ptr), length))
+	if err == nil && n < length {
+		err = io.ErrShortWrite
+	}
+
+	if err != nil {
+		writer.err = err
+		return C.int(n / int(size))
+	}
+
+	return C.int(nmemb)
+}
+
+//golibrdfParserNamespace is called by raptor when a namespace is declared in data parsed by the Parser held by handle
+//
+//export golibrdfParserNamespace
+func golibrdfParserNamespace(handle C.uintptr_t, prefix *C.char, uri *C.char) {
+	parser := cgo.Handle(handle).Value().(*Parser)
+
+	parser.readerNamespaces = appendNamespace(parser.readerNamespaces, Namespace{Prefix: C.GoString(prefix), Uri: C.GoString(uri)})
+}
+
+//golibrdfLogMessage is called by librdf when a message is logged for the World whose worldLog is held by handle
+//	collector holds the logCollector of the operation running on the thread that logged the message, or is 0 if there is none.
+//	A non-zero return tells librdf that the message has been handled
+//
+//export golibrdfLogMessage
+func golibrdfLogMessage(handle C.uintptr_t, collector C.uintptr_t, message *C.librdf_log_message) C.int {
+	log := cgo.Handle(handle).Value().(*worldLog)
+
+	var operation *logCollector
+	if collector != 0 {
+		operation = cgo.Handle(collector).Value().(*logCollector)
+	}
+
+	log.handle(newLogMessage(message), operation)
+
+	return 1
+}
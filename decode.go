@@ -0,0 +1,174 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+)
+
+var (
+	nodePointerType     = reflect.TypeOf((*Node)(nil))
+	urlPointerType      = reflect.TypeOf((*url.URL)(nil))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	bigIntPointerType   = reflect.TypeOf((*big.Int)(nil))
+	bigRatPointerType   = reflect.TypeOf((*big.Rat)(nil))
+	emptyInterfaceType  = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+//assignNode sets a Go value from a node, converting the node's term to the value's type
+//	A nil node sets the zero value
+func assignNode(target reflect.Value, node *Node) error {
+	if node.isUnbound() {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	targetType := target.Type()
+
+	switch {
+	case targetType == nodePointerType:
+		target.Set(reflect.ValueOf(node.clone()))
+		return nil
+	case targetType == urlPointerType && node.IsResource():
+		parsed, err := url.Parse(node.GetUriString())
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(parsed))
+		return nil
+	case targetType.Kind() == reflect.String:
+		// string fields receive the lexical form of any literal, whatever its datatype
+		switch {
+		case node.IsLiteral():
+			target.SetString(node.GetLiteralValue())
+		case node.IsBlank():
+			target.SetString(node.GetBlankIdentifier())
+		default:
+			target.SetString(node.GetUriString())
+		}
+		return nil
+	case targetType.Kind() == reflect.Ptr && targetType != bigIntPointerType && targetType != bigRatPointerType &&
+		targetType != urlPointerType && !targetType.Implements(textUnmarshalerType):
+		// optional values such as *int are allocated and the node assigned to the element
+		element := reflect.New(targetType.Elem())
+		if err := assignNode(element.Elem(), node); err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+	}
+
+	if !node.IsLiteral() {
+		return fmt.Errorf("unable to assign %s to a value of type %s", node.String(), targetType)
+	}
+
+	value, err := node.Value()
+	if err != nil {
+		return err
+	}
+
+	if err = assignValue(target, value); err == nil {
+		return nil
+	}
+
+	// types such as net.IP that are not produced by Node.Value may be decoded from the lexical form
+	if target.CanAddr() && target.Addr().Type().Implements(textUnmarshalerType) {
+		return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(node.GetLiteralValue()))
+	}
+
+	return err
+}
+
+//assignValue sets a Go value from a value returned by Node.Value, converting between numeric types where the value fits
+func assignValue(target reflect.Value, value interface{}) error {
+	source := reflect.ValueOf(value)
+	targetType := target.Type()
+
+	if targetType == emptyInterfaceType || source.Type().AssignableTo(targetType) {
+		target.Set(source)
+		return nil
+	}
+
+	switch targetType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := integerValue(value)
+		if !ok || !integer.IsInt64() || target.OverflowInt(integer.Int64()) {
+			break
+		}
+		target.SetInt(integer.Int64())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		integer, ok := integerValue(value)
+		if !ok || !integer.IsUint64() || target.OverflowUint(integer.Uint64()) {
+			break
+		}
+		target.SetUint(integer.Uint64())
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch v := value.(type) {
+		case float32:
+			target.SetFloat(float64(v))
+			return nil
+		case float64:
+			if target.OverflowFloat(v) {
+				break
+			}
+			target.SetFloat(v)
+			return nil
+		case *big.Rat:
+			f, _ := v.Float64()
+			target.SetFloat(f)
+			return nil
+		}
+		if integer, ok := integerValue(value); ok {
+			f, _ := new(big.Float).SetInt(integer).Float64()
+			target.SetFloat(f)
+			return nil
+		}
+	}
+
+	if target.Kind() == reflect.Ptr && targetType.Elem() == source.Type() {
+		element := reflect.New(targetType.Elem())
+		element.Elem().Set(source)
+		target.Set(element)
+		return nil
+	}
+
+	return fmt.Errorf("unable to assign a value of type %T to a value of type %s", value, targetType)
+}
+
+//integerValue returns an integer value produced by Node.Value as a *big.Int
+func integerValue(value interface{}) (*big.Int, bool) {
+	switch v := value.(type) {
+	case int64:
+		return big.NewInt(v), true
+	case uint64:
+		return new(big.Int).SetUint64(v), true
+	case *big.Int:
+		return v, true
+	}
+	return nil, false
+}
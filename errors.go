@@ -0,0 +1,274 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+import (
+	"errors"
+	"strconv"
+)
+
+//errors that may be tested for with errors.Is
+var (
+	//ErrWorldClosed is returned when a World is used before it has been opened or after it has been closed
+	ErrWorldClosed = errors.New("World is not open.")
+
+	//ErrWorldAlreadyOpen is returned when Open is called on a World that is already open
+	ErrWorldAlreadyOpen = errors.New("World is already open.")
+
+	//ErrWorldReopened is returned when Open is called on a World that has been closed
+	ErrWorldReopened = errors.New("World has previously been opened.")
+
+	//ErrFreed is returned when an object is used after Free has been called
+	ErrFreed = errors.New("Object has been freed.")
+
+	//ErrNilArgument is returned when a required argument is nil or has been freed
+	ErrNilArgument = errors.New("Required argument is nil or has been freed.")
+
+	//ErrLibrdfFailed is returned when a librdf call reports a failure without further detail
+	ErrLibrdfFailed = errors.New("Call to librdf failed.")
+
+	//ErrNullResult is returned when librdf returns null where a result was expected
+	ErrNullResult = errors.New("librdf returned null.")
+
+	//ErrResultForm is returned when query results are read in a form they do not have, such as bindings from an ASK query
+	ErrResultForm = errors.New("Query results are not of the required form.")
+
+	//ErrTxDone is returned when a Tx is used after it has been committed or rolled back
+	ErrTxDone = errors.New("Transaction has already ended.")
+
+	//ErrUnsupportedSyntax is returned when no parser or serializer supports a requested syntax
+	ErrUnsupportedSyntax = errors.New("Syntax is not supported.")
+
+	//ErrSyntax is matched by a *ParseError, so that errors.Is can identify invalid RDF data or query syntax
+	ErrSyntax = errors.New("Invalid syntax.")
+
+	//ErrFetch is matched by a *FetchError, so that errors.Is can identify data that could not be retrieved for parsing
+	ErrFetch = errors.New("Unable to retrieve data.")
+)
+
+//WorldError records a failed World operation
+type WorldError struct {
+	Op  string
+	Err error
+}
+
+func (err *WorldError) Error() string {
+	return formatOpError(err.Op, "", "", err.Err)
+}
+
+func (err *WorldError) Unwrap() error {
+	return err.Err
+}
+
+//UriError records a failed Uri operation along with the URI string involved, if any
+type UriError struct {
+	Op  string
+	Uri string
+	Err error
+}
+
+func (err *UriError) Error() string {
+	return formatOpError(err.Op, "", err.Uri, err.Err)
+}
+
+func (err *UriError) Unwrap() error {
+	return err.Err
+}
+
+//StorageError records a failed Storage operation along with the storage module name, such as "memory" or "hashes"
+type StorageError struct {
+	Op          string
+	StorageName string
+	Err         error
+}
+
+func (err *StorageError) Error() string {
+	return formatOpError(err.Op, "module", err.StorageName, err.Err)
+}
+
+func (err *StorageError) Unwrap() error {
+	return err.Err
+}
+
+//NodeError records a failed Node operation
+type NodeError struct {
+	Op  string
+	Err error
+}
+
+func (err *NodeError) Error() string {
+	return formatOpError(err.Op, "", "", err.Err)
+}
+
+func (err *NodeError) Unwrap() error {
+	return err.Err
+}
+
+//StatementError records a failed Statement operation
+type StatementError struct {
+	Op  string
+	Err error
+}
+
+func (err *StatementError) Error() string {
+	return formatOpError(err.Op, "", "", err.Err)
+}
+
+func (err *StatementError) Unwrap() error {
+	return err.Err
+}
+
+//ModelError records a failed Model operation
+type ModelError struct {
+	Op  string
+	Err error
+}
+
+func (err *ModelError) Error() string {
+	return formatOpError(err.Op, "", "", err.Err)
+}
+
+func (err *ModelError) Unwrap() error {
+	return err.Err
+}
+
+//TxError records a failed Tx operation
+type TxError struct {
+	Op  string
+	Err error
+}
+
+func (err *TxError) Error() string {
+	return formatOpError(err.Op, "", "", err.Err)
+}
+
+func (err *TxError) Unwrap() error {
+	return err.Err
+}
+
+//QueryError records a failed Query operation along with the query language name, such as "sparql"
+//	A query that cannot be parsed has a *ParseError as its Err
+type QueryError struct {
+	Op       string
+	Language string
+	Err      error
+}
+
+func (err *QueryError) Error() string {
+	return formatOpError(err.Op, "language", err.Language, err.Err)
+}
+
+func (err *QueryError) Unwrap() error {
+	return err.Err
+}
+
+//ParserError records a failed Parser operation other than parsing itself, along with the syntax name
+//	Data that cannot be parsed is reported with a *ParseError
+type ParserError struct {
+	Op     string
+	Syntax string
+	Err    error
+}
+
+func (err *ParserError) Error() string {
+	return formatOpError(err.Op, "syntax", err.Syntax, err.Err)
+}
+
+func (err *ParserError) Unwrap() error {
+	return err.Err
+}
+
+//SerializerError records a failed Serializer operation along with the syntax name
+type SerializerError struct {
+	Op     string
+	Syntax string
+	Err    error
+}
+
+func (err *SerializerError) Error() string {
+	return formatOpError(err.Op, "syntax", err.Syntax, err.Err)
+}
+
+func (err *SerializerError) Unwrap() error {
+	return err.Err
+}
+
+//formatOpError formats the message for a failed operation in the form `Unable to <op> for <kind> "<subject>".  <err>`
+//	The subject is omitted when empty, and "for <kind>" when kind is empty
+func formatOpError(op string, kind string, subject string, err error) string {
+	text := "Unable to " + op
+	if subject != "" {
+		if kind != "" {
+			text += " for " + kind
+		}
+		text += " " + strconv.Quote(subject)
+	}
+	text += "."
+
+	if err != nil {
+		text += "  " + err.Error()
+	}
+
+	return text
+}
+
+//checkWorld returns ErrWorldClosed unless the world is open
+func checkWorld(world *World) error {
+	if world == nil || world.librdf_world == nil {
+		return ErrWorldClosed
+	}
+	return nil
+}
+
+//checkNodes returns ErrNilArgument if any of the nodes is nil or has been freed
+func checkNodes(nodes ...*Node) error {
+	for _, node := range nodes {
+		if node == nil || node.librdf_node == nil {
+			return ErrNilArgument
+		}
+	}
+	return nil
+}
+
+//checkStatement returns ErrNilArgument if the statement is nil or has been freed
+func checkStatement(statement *Statement) error {
+	if statement == nil || statement.librdf_statement == nil {
+		return ErrNilArgument
+	}
+	return nil
+}
+
+//checkModel returns ErrNilArgument if the model is nil or has been freed
+func checkModel(model *Model) error {
+	if model == nil || model.librdf_model == nil {
+		return ErrNilArgument
+	}
+	return nil
+}
+
+//checkUri returns ErrNilArgument if the uri is nil or has been freed
+func checkUri(uri *Uri) error {
+	if uri == nil || uri.librdf_uri == nil {
+		return ErrNilArgument
+	}
+	return nil
+}
@@ -22,11 +22,20 @@
 package golibrdf
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
+	"math/big"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 const rdfxml_content string = `<?xml version="1.0"?>
@@ -544,6 +553,7 @@ func Test_ModelLoadAndSerialize(t *testing.T) {
 //Test_ModelFileStorageAddStatement is based on Example7 from the librdf library and tests the following sequence:
 //	- Creating a model backed with file storage 
 //	- Adding a statement to the model
+//	- Syncing the model to disk and checking the statement is counted by a second model using the same file
 func Test_ModelFileStorageAddStatement(t *testing.T) {
 
 	storageType, storageOptions := "file", ""
@@ -579,6 +589,42 @@ func Test_ModelFileStorageAddStatement(t *testing.T) {
 	defer statement.Free()
 
 	model.AddStatement(statement)
+
+	if err = model.Sync(); err != nil {
+		t.Fatalf("Failed to sync model: %s", err.Error())
+	}
+
+	var size int
+	if size, err = model.Size(); err != nil {
+		t.Fatalf("Failed to determine model size: %s", err.Error())
+	}
+
+	if size < 1 {
+		t.Fatalf("Model size is %d after adding a statement", size)
+	}
+
+	// construct a second model over the same file to check the statement was persisted
+	var persistedStorage *Storage
+	var persistedModel *Model
+
+	if persistedStorage, err = NewStorage(world, storageType, "./testoutput/Test_ModelFileStorageAddStatement_file.rdf", storageOptions); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer persistedStorage.Free()
+
+	if persistedModel, err = NewModel(world, persistedStorage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer persistedModel.Free()
+
+	var count int
+	if count, err = persistedModel.Count(statement); err != nil {
+		t.Fatalf("Failed to count statements: %s", err.Error())
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected 1 persisted statement, found %d", count)
+	}
 }
 
 //Test_ParseAndQueryToFormattedString is based on Example8 from the librdf library and tests the following sequence:
@@ -709,3 +755,2972 @@ func Test_ParseStringIntoModel(t *testing.T) {
 	// print out the model
 	fmt.Printf("Resulting model is:\n%s", model.ToString())
 }
+
+//Test_TypedLiteralRoundTrip tests the following sequence:
+//	- Creating typed literal nodes with XSD datatypes
+//	- Adding statements using these nodes to a model
+//	- Serializing the model and checking that the datatypes are retained
+func Test_TypedLiteralRoundTrip(t *testing.T) {
+	storageType := "memory"
+	xsdInteger := "http://www.w3.org/2001/XMLSchema#integer"
+	xsdDate := "http://www.w3.org/2001/XMLSchema#date"
+
+	var err error
+	var storage *Storage
+	var model *Model
+	var serializer *Serializer
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	var datatypeUri *Uri
+	if datatypeUri, err = NewUri(world, xsdInteger); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer datatypeUri.Free()
+
+	var integerNode, dateNode *Node
+	if integerNode, err = NewNodeFromTypedLiteral(world, "42", datatypeUri); err != nil {
+		t.Fatalf("Failed to create typed literal node: %s", err.Error())
+	}
+
+	if !integerNode.IsLiteral() {
+		t.Fatalf("Typed literal node returned IsLiteral() == false")
+	}
+
+	if value := integerNode.GetLiteralValue(); value != "42" {
+		t.Fatalf("GetLiteralValue() returned %s rather than the value used to construct the node", value)
+	}
+
+	if nodeDatatypeUri := integerNode.GetLiteralValueDatatypeUri(); nodeDatatypeUri == nil || nodeDatatypeUri.ToString() != xsdInteger {
+		t.Fatalf("GetLiteralValueDatatypeUri() did not return the datatype used to construct the node")
+	}
+
+	if dateNode, err = NewNodeFromTypedLiteralUriString(world, "2024-01-01", xsdDate); err != nil {
+		t.Fatalf("Failed to create typed literal node: %s", err.Error())
+	}
+
+	var subject, predicate *Node
+	var statement, dateStatement *Statement
+
+	if subject, err = NewNodeFromUriString(world, "http://example.org/subject"); err != nil {
+		t.Fatalf("Failed to create subject node: %s", err.Error())
+	}
+
+	if predicate, err = NewNodeFromUriString(world, "http://example.org/count"); err != nil {
+		t.Fatalf("Failed to create predicate node: %s", err.Error())
+	}
+
+	if statement, err = NewStatementFromNodes(world, subject, predicate, integerNode); err != nil {
+		t.Fatalf("Failed to create statement from nodes: %s", err.Error())
+	}
+	defer statement.Free() // note: this will free the attached nodes
+
+	if err = model.AddStatement(statement); err != nil {
+		t.Fatalf("Failed to add statement: %s", err.Error())
+	}
+
+	if subject, err = NewNodeFromUriString(world, "http://example.org/subject"); err != nil {
+		t.Fatalf("Failed to create subject node: %s", err.Error())
+	}
+
+	if predicate, err = NewNodeFromUriString(world, "http://example.org/date"); err != nil {
+		t.Fatalf("Failed to create predicate node: %s", err.Error())
+	}
+
+	if dateStatement, err = NewStatementFromNodes(world, subject, predicate, dateNode); err != nil {
+		t.Fatalf("Failed to create statement from nodes: %s", err.Error())
+	}
+	defer dateStatement.Free()
+
+	if err = model.AddStatement(dateStatement); err != nil {
+		t.Fatalf("Failed to add statement: %s", err.Error())
+	}
+
+	if serializer, err = NewSerializer(world, "ntriples", "", nil); err != nil {
+		t.Fatalf("Failed to create serializer: %s", err.Error())
+	}
+	defer serializer.Free()
+
+	var modelString string
+	if modelString, err = serializer.SerializeModelToString(model, nil); err != nil {
+		t.Fatalf("Failed to serialize model: %s", err.Error())
+	}
+	fmt.Printf("Serialised model: %s", modelString)
+
+	if !strings.Contains(modelString, "\"42\"^^<"+xsdInteger+">") {
+		t.Fatalf("Serialised model does not retain the xsd:integer datatype")
+	}
+
+	if !strings.Contains(modelString, "\"2024-01-01\"^^<"+xsdDate+">") {
+		t.Fatalf("Serialised model does not retain the xsd:date datatype")
+	}
+
+	var xmlNode *Node
+	if xmlNode, err = NewNodeFromXmlLiteral(world, "<b>bold</b>", ""); err != nil {
+		t.Fatalf("Failed to create xml literal node: %s", err.Error())
+	}
+	defer xmlNode.Free()
+
+	if !xmlNode.IsWellFormedXML() {
+		t.Fatalf("XML literal node returned IsWellFormedXML() == false")
+	}
+
+	if integerNode.IsWellFormedXML() {
+		t.Fatalf("Typed literal node returned IsWellFormedXML() == true")
+	}
+}
+
+//Test_NodeValueConversion tests the following sequence:
+//	- Creating literal nodes from Go values
+//	- Converting the literal nodes back to Go values
+//	- Checking that lexically invalid literals are reported as errors
+func Test_NodeValueConversion(t *testing.T) {
+	var err error
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	when := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	values := []interface{}{"text", true, int64(42), 1.5, float32(0.25), big.NewRat(3, 8), when, 90 * time.Minute, []byte("bytes")}
+
+	for _, value := range values {
+		var node *Node
+		if node, err = NewNodeFromValue(world, value); err != nil {
+			t.Fatalf("Failed to create node from value %v: %s", value, err.Error())
+		}
+
+		var converted interface{}
+		if converted, err = node.Value(); err != nil {
+			t.Fatalf("Failed to convert node to value: %s", err.Error())
+		}
+
+		if fmt.Sprintf("%T %v", converted, converted) != fmt.Sprintf("%T %v", value, value) {
+			t.Fatalf("Value %T %v was converted back to %T %v", value, value, converted, converted)
+		}
+		node.Free()
+	}
+
+	var dateNode *Node
+	if dateNode, err = NewNodeFromTypedLiteralUriString(world, "2024-01-01", XsdDate); err != nil {
+		t.Fatalf("Failed to create typed literal node: %s", err.Error())
+	}
+	defer dateNode.Free()
+
+	if date, err := dateNode.Value(); err != nil || !date.(time.Time).Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("xsd:date literal was not converted to the expected time")
+	}
+
+	var invalidNode *Node
+	if invalidNode, err = NewNodeFromTypedLiteralUriString(world, "forty-two", XsdInteger); err != nil {
+		t.Fatalf("Failed to create typed literal node: %s", err.Error())
+	}
+	defer invalidNode.Free()
+
+	if _, err = invalidNode.Value(); err == nil {
+		t.Fatalf("Lexically invalid xsd:integer literal was converted without error")
+	}
+	fmt.Printf("Invalid literal error: %s\n", err.Error())
+
+	var resourceNode *Node
+	if resourceNode, err = NewNodeFromUriString(world, "http://example.org/subject"); err != nil {
+		t.Fatalf("Failed to create node from uri: %s", err.Error())
+	}
+	defer resourceNode.Free()
+
+	if _, err = resourceNode.Value(); err == nil {
+		t.Fatalf("Resource node was converted to a value without error")
+	}
+}
+
+//Test_BlankNodes tests the following sequence:
+//	- Creating blank nodes with generated and explicit identifiers
+//	- Building structured data (an address) using a blank node
+//	- Finding the blank node again through the model
+func Test_BlankNodes(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	var generatedNode, namedNode *Node
+	if generatedNode, err = NewBlankNode(world); err != nil {
+		t.Fatalf("Failed to create blank node: %s", err.Error())
+	}
+	defer generatedNode.Free()
+
+	if !generatedNode.IsBlank() {
+		t.Fatalf("Blank node returned IsBlank() == false")
+	}
+
+	if generatedNode.GetBlankIdentifier() == "" {
+		t.Fatalf("Blank node has no generated identifier")
+	}
+
+	if namedNode, err = NewBlankNodeFromId(world, "address1"); err != nil {
+		t.Fatalf("Failed to create blank node from identifier: %s", err.Error())
+	}
+
+	if id := namedNode.GetBlankIdentifier(); id != "address1" {
+		t.Fatalf("GetBlankIdentifier() returned %s rather than the identifier used to construct the node", id)
+	}
+
+	// link a person to an address held in the blank node
+	var subject, predicate *Node
+
+	if subject, err = NewNodeFromUriString(world, "http://example.org/person"); err != nil {
+		t.Fatalf("Failed to create subject node: %s", err.Error())
+	}
+
+	if predicate, err = NewNodeFromUriString(world, "http://example.org/address"); err != nil {
+		t.Fatalf("Failed to create predicate node: %s", err.Error())
+	}
+
+	statement, err := NewStatementFromNodes(world, subject, predicate, namedNode)
+	if err != nil {
+		t.Fatalf("Failed to create statement from nodes: %s", err.Error())
+	}
+	defer statement.Free() // note: this will free the attached nodes
+
+	if err = model.AddStatement(statement); err != nil {
+		t.Fatalf("Failed to add statement: %s", err.Error())
+	}
+
+	var addressSubject, addressPredicate, addressObject *Node
+
+	if addressSubject, err = NewBlankNodeFromId(world, "address1"); err != nil {
+		t.Fatalf("Failed to create blank node from identifier: %s", err.Error())
+	}
+
+	if addressPredicate, err = NewNodeFromUriString(world, "http://example.org/city"); err != nil {
+		t.Fatalf("Failed to create predicate node: %s", err.Error())
+	}
+
+	if addressObject, err = NewNodeFromLiteral(world, "Brisbane"); err != nil {
+		t.Fatalf("Failed to create object node: %s", err.Error())
+	}
+
+	addressStatement, err := NewStatementFromNodes(world, addressSubject, addressPredicate, addressObject)
+	if err != nil {
+		t.Fatalf("Failed to create statement from nodes: %s", err.Error())
+	}
+	defer addressStatement.Free()
+
+	if err = model.AddStatement(addressStatement); err != nil {
+		t.Fatalf("Failed to add statement: %s", err.Error())
+	}
+
+	fmt.Printf("%s: Resulting model is:\n%s", os.Args[0], model.ToString())
+
+	count := 0
+	for target := range model.FindTargets(subject, predicate, 10) {
+		if !target.IsBlank() || target.GetBlankIdentifier() != "address1" {
+			t.Fatalf("Target of the address predicate is not the blank node that was added")
+		}
+		count = count + 1
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected 1 target for the address predicate, found %d", count)
+	}
+}
+
+//Test_NodeEqualityOrderingAndKeys tests the following sequence:
+//	- Comparing nodes for equality
+//	- Using node keys to remove duplicate nodes
+//	- Sorting nodes using the SPARQL ORDER BY ordering
+func Test_NodeEqualityOrderingAndKeys(t *testing.T) {
+	var err error
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	var first, second, other, blank, ten, two, text *Node
+
+	if first, err = NewNodeFromUriString(world, "http://example.org/a"); err != nil {
+		t.Fatalf("Failed to create resource node: %s", err.Error())
+	}
+
+	if second, err = NewNodeFromUriString(world, "http://example.org/a"); err != nil {
+		t.Fatalf("Failed to create resource node: %s", err.Error())
+	}
+
+	if other, err = NewNodeFromUriString(world, "http://example.org/b"); err != nil {
+		t.Fatalf("Failed to create resource node: %s", err.Error())
+	}
+
+	if blank, err = NewBlankNodeFromId(world, "b0"); err != nil {
+		t.Fatalf("Failed to create blank node: %s", err.Error())
+	}
+
+	if ten, err = NewNodeFromTypedLiteralUriString(world, "10", XsdInteger); err != nil {
+		t.Fatalf("Failed to create typed literal node: %s", err.Error())
+	}
+
+	if two, err = NewNodeFromTypedLiteralUriString(world, "2", XsdInteger); err != nil {
+		t.Fatalf("Failed to create typed literal node: %s", err.Error())
+	}
+
+	if text, err = NewNodeFromLiteral(world, "text"); err != nil {
+		t.Fatalf("Failed to create literal node: %s", err.Error())
+	}
+
+	nodes := []*Node{text, ten, first, two, second, other, blank}
+	for _, node := range nodes {
+		defer node.Free()
+	}
+
+	if !first.Equals(second) {
+		t.Fatalf("Nodes with the same URI returned Equals() == false")
+	}
+
+	if first.Equals(other) || first.Equals(text) {
+		t.Fatalf("Different nodes returned Equals() == true")
+	}
+
+	if first.Key() != second.Key() || first.Key() == other.Key() {
+		t.Fatalf("Node keys do not match node equality")
+	}
+
+	distinct := make(map[NodeKey]*Node)
+	for _, node := range nodes {
+		distinct[node.Key()] = node
+	}
+
+	if len(distinct) != len(nodes)-1 {
+		t.Fatalf("Expected %d distinct nodes, found %d", len(nodes)-1, len(distinct))
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Compare(nodes[j]) < 0 })
+
+	expected := []*Node{blank, first, second, other, two, ten, text}
+	for i, node := range nodes {
+		if !node.Equals(expected[i]) {
+			t.Fatalf("Node at position %d is %s, expected %s", i, node.Key(), expected[i].Key())
+		}
+	}
+
+	if first.Compare(second) != 0 {
+		t.Fatalf("Equal nodes returned a non zero Compare() result")
+	}
+}
+
+//Test_NodeTextMarshalling tests the following sequence:
+//	- Parsing nodes from N-Triples terms
+//	- Formatting nodes as N-Triples terms through String and MarshalText
+//	- Unmarshalling N-Triples terms into nodes
+func Test_NodeTextMarshalling(t *testing.T) {
+	var err error
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	terms := []string{
+		"<http://example.org/subject>",
+		"\"literal\"@en",
+		"\"1\"^^<http://www.w3.org/2001/XMLSchema#int>",
+		"\"quote \\\" and newline \\n\"",
+		"_:b0",
+	}
+
+	for _, term := range terms {
+		var node *Node
+		if node, err = ParseNode(world, term); err != nil {
+			t.Fatalf("Failed to parse node: %s", err.Error())
+		}
+
+		if node.String() != term {
+			t.Fatalf("Node parsed from %s has String() %s", term, node.String())
+		}
+
+		var text []byte
+		if text, err = node.MarshalText(); err != nil {
+			t.Fatalf("Failed to marshal node: %s", err.Error())
+		}
+
+		var unmarshalledNode *Node
+		if unmarshalledNode, err = NewNode(world); err != nil {
+			t.Fatalf("Failed to create node: %s", err.Error())
+		}
+
+		if err = unmarshalledNode.UnmarshalText(text); err != nil {
+			t.Fatalf("Failed to unmarshal node: %s", err.Error())
+		}
+
+		if !unmarshalledNode.Equals(node) {
+			t.Fatalf("Unmarshalled node %s does not equal the original node %s", unmarshalledNode, node)
+		}
+
+		node.Free()
+		unmarshalledNode.Free()
+	}
+
+	if _, err = ParseNode(world, "http://example.org/missing-brackets"); err == nil {
+		t.Fatalf("Invalid term was parsed without error")
+	}
+}
+
+//Test_ModelContexts tests the following sequence:
+//	- Creating a model backed by storage with contexts enabled
+//	- Adding statements to separate contexts (named graphs)
+//	- Listing contexts and finding statements within a context
+//	- Removing all statements in a context
+func Test_ModelContexts(t *testing.T) {
+	storageType := "hashes"
+	storageOptions := "hash-type='memory',contexts='yes'"
+
+	var err error
+	var storage *Storage
+	var model *Model
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", storageOptions); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if !model.SupportsContexts() {
+		t.Fatalf("Model returned SupportsContexts() == false for storage with contexts enabled")
+	}
+
+	sourceA, err := NewNodeFromUriString(world, "http://example.org/sourceA")
+	if err != nil {
+		t.Fatalf("Failed to create context node: %s", err.Error())
+	}
+	defer sourceA.Free()
+
+	sourceB, err := NewNodeFromUriString(world, "http://example.org/sourceB")
+	if err != nil {
+		t.Fatalf("Failed to create context node: %s", err.Error())
+	}
+	defer sourceB.Free()
+
+	contextObjects := []struct {
+		context *Node
+		object  string
+	}{{sourceA, "first"}, {sourceA, "second"}, {sourceB, "third"}}
+
+	for _, contextObject := range contextObjects {
+		var subject, predicate, object *Node
+
+		if subject, err = NewNodeFromUriString(world, "http://example.org/subject"); err != nil {
+			t.Fatalf("Failed to create subject node: %s", err.Error())
+		}
+
+		if predicate, err = NewNodeFromUriString(world, "http://example.org/pred1"); err != nil {
+			t.Fatalf("Failed to create predicate node: %s", err.Error())
+		}
+
+		if object, err = NewNodeFromLiteral(world, contextObject.object); err != nil {
+			t.Fatalf("Failed to create object node: %s", err.Error())
+		}
+
+		statement, err := NewStatementFromNodes(world, subject, predicate, object)
+		if err != nil {
+			t.Fatalf("Failed to create statement from nodes: %s", err.Error())
+		}
+
+		if err = model.AddStatementWithContext(contextObject.context, statement); err != nil {
+			t.Fatalf("Failed to add statement with context: %s", err.Error())
+		}
+		statement.Free()
+	}
+
+	count := 0
+	for context := range model.GetContexts(10) {
+		if !context.Equals(sourceA) && !context.Equals(sourceB) {
+			t.Fatalf("Unexpected context %s", context)
+		}
+		count = count + 1
+		context.Free()
+	}
+
+	if count != 2 {
+		t.Fatalf("Expected 2 contexts, found %d", count)
+	}
+
+	count = 0
+	for statement := range model.FindStatementsInContext(nil, sourceA, 10) {
+		count = count + 1
+		statement.Free()
+	}
+
+	if count != 2 {
+		t.Fatalf("Expected 2 statements in context, found %d", count)
+	}
+
+	if err = model.RemoveContextStatements(sourceA); err != nil {
+		t.Fatalf("Failed to remove context statements: %s", err.Error())
+	}
+
+	if model.ContainsContext(sourceA) {
+		t.Fatalf("Model still contains context after its statements were removed")
+	}
+
+	if !model.ContainsContext(sourceB) {
+		t.Fatalf("Model no longer contains a context that was not removed")
+	}
+}
+
+//Test_ModelTransactions tests the following sequence against memory and hashes storage:
+//	- Adding statements within a transaction and rolling back
+//	- Adding statements within a transaction and committing
+//	- Removing a statement within a transaction and rolling back
+//	- Removing a statement held in a context without giving the context and rolling back
+//	- Ending a transaction after its model has been freed
+func Test_ModelTransactions(t *testing.T) {
+	storages := []struct {
+		storageType    string
+		storageOptions string
+	}{{"memory", ""}, {"hashes", "hash-type='memory'"}, {"hashes", "hash-type='memory',contexts='yes'"}}
+
+	var err error
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	for _, storageConfig := range storages {
+		var storage *Storage
+		var model *Model
+		var tx *Tx
+
+		// construct a storage provider
+		if storage, err = NewStorage(world, storageConfig.storageType, "test", storageConfig.storageOptions); err != nil {
+			t.Fatalf("Failed to create storage: %s", err.Error())
+		}
+		defer storage.Free()
+
+		// construct a model
+		if model, err = NewModel(world, storage, ""); err != nil {
+			t.Fatalf("Failed to construct model: %s", err.Error())
+		}
+		defer model.Free()
+
+		var subject, predicate, object *Node
+		var statement *Statement
+
+		if subject, err = NewNodeFromUriString(world, "http://example.org/subject"); err != nil {
+			t.Fatalf("Failed to create subject node: %s", err.Error())
+		}
+
+		if predicate, err = NewNodeFromUriString(world, "http://example.org/pred1"); err != nil {
+			t.Fatalf("Failed to create predicate node: %s", err.Error())
+		}
+
+		if object, err = NewNodeFromLiteral(world, "object"); err != nil {
+			t.Fatalf("Failed to create object node: %s", err.Error())
+		}
+
+		if statement, err = NewStatementFromNodes(world, subject, predicate, object); err != nil {
+			t.Fatalf("Failed to create statement from nodes: %s", err.Error())
+		}
+		defer statement.Free()
+
+		// add and roll back
+		if tx, err = model.Begin(); err != nil {
+			t.Fatalf("Failed to begin transaction: %s", err.Error())
+		}
+		fmt.Printf("%s storage native transaction: %t\n", storageConfig.storageType, tx.IsNative())
+
+		if err = tx.AddStatement(statement); err != nil {
+			t.Fatalf("Failed to add statement in transaction: %s", err.Error())
+		}
+
+		if err = tx.Rollback(); err != nil {
+			t.Fatalf("Failed to roll back transaction: %s", err.Error())
+		}
+
+		if model.ContainsStatement(statement) {
+			t.Fatalf("%s storage contains a statement added in a transaction that was rolled back", storageConfig.storageType)
+		}
+
+		// add and commit
+		if tx, err = model.Begin(); err != nil {
+			t.Fatalf("Failed to begin transaction: %s", err.Error())
+		}
+
+		if err = tx.AddStatement(statement); err != nil {
+			t.Fatalf("Failed to add statement in transaction: %s", err.Error())
+		}
+
+		if err = tx.Commit(); err != nil {
+			t.Fatalf("Failed to commit transaction: %s", err.Error())
+		}
+
+		if !model.ContainsStatement(statement) {
+			t.Fatalf("%s storage does not contain a statement added in a committed transaction", storageConfig.storageType)
+		}
+
+		if err = tx.AddStatement(statement); err == nil {
+			t.Fatalf("Statement was added to a transaction that had already ended")
+		}
+
+		// remove and roll back
+		if tx, err = model.Begin(); err != nil {
+			t.Fatalf("Failed to begin transaction: %s", err.Error())
+		}
+
+		if err = tx.RemoveStatement(statement); err != nil {
+			t.Fatalf("Failed to remove statement in transaction: %s", err.Error())
+		}
+
+		if err = tx.Rollback(); err != nil {
+			t.Fatalf("Failed to roll back transaction: %s", err.Error())
+		}
+
+		if !model.ContainsStatement(statement) {
+			t.Fatalf("%s storage does not contain a statement removed in a transaction that was rolled back", storageConfig.storageType)
+		}
+
+		// remove a statement held in a context without giving the context, and roll back
+		if model.SupportsContexts() {
+			var contextNode *Node
+			if contextNode, err = NewNodeFromUriString(world, "http://example.org/graph"); err != nil {
+				t.Fatalf("Failed to create context node: %s", err.Error())
+			}
+			defer contextNode.Free()
+
+			if err = model.AddStatementWithContext(contextNode, statement); err != nil {
+				t.Fatalf("Failed to add statement with context: %s", err.Error())
+			}
+
+			if tx, err = model.Begin(); err != nil {
+				t.Fatalf("Failed to begin transaction: %s", err.Error())
+			}
+
+			if err = tx.RemoveStatement(statement); err != nil {
+				t.Fatalf("Failed to remove statement in transaction: %s", err.Error())
+			}
+
+			if err = tx.Rollback(); err != nil {
+				t.Fatalf("Failed to roll back transaction: %s", err.Error())
+			}
+
+			if !model.containsStatementWithOptionalContext(contextNode, statement) {
+				t.Fatalf("%s storage did not restore a statement to its context on roll back", storageConfig.storageType)
+			}
+		}
+
+		// a transaction that outlives its model reports the model as freed
+		if tx, err = model.Begin(); err != nil {
+			t.Fatalf("Failed to begin transaction: %s", err.Error())
+		}
+		model.Free()
+
+		if err = tx.Commit(); !errors.Is(err, ErrFreed) {
+			t.Fatalf("Expected ErrFreed committing a transaction on a freed model, got %v", err)
+		}
+	}
+}
+
+//Test_CancelFindStatementsAndQuery tests the following sequence:
+//	- Parsing RDFXML into a model
+//	- Reading a single statement, target and query result and then cancelling the iteration
+//	- Checking that the channels are closed and the cancellation is reported as an error
+func Test_CancelFindStatementsAndQuery(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var uri *Uri
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	if uri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uri.Free()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if err = parser.ParseIntoModel(uri, nil, model); err != nil {
+		t.Fatalf("Failed to parse uri into model: %s", err.Error())
+	}
+
+	var partialStatement *Statement
+	if partialStatement, err = NewStatement(world); err != nil {
+		t.Fatalf("Failed to create partial statement: %s", err.Error())
+	}
+	defer partialStatement.Free()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chanStatements, chanErr := model.FindStatementsCtx(ctx, partialStatement, 0)
+
+	statement := <-chanStatements
+	statement.Free()
+	cancel()
+
+	for statement := range chanStatements {
+		statement.Free()
+	}
+
+	if err = <-chanErr; err != context.Canceled {
+		t.Fatalf("Expected cancellation error from FindStatementsCtx, got %v", err)
+	}
+
+	subject, err := NewNodeFromUriString(world, "http://purl.org/net/dajobe/")
+	if err != nil {
+		t.Fatalf("Failed to create subject node: %s", err.Error())
+	}
+	defer subject.Free()
+
+	predicate, err := NewNodeFromUriString(world, "http://purl.org/dc/elements/1.1/title")
+	if err != nil {
+		t.Fatalf("Failed to create predicate node: %s", err.Error())
+	}
+	defer predicate.Free()
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	chanTargets, chanErr := model.FindTargetsCtx(ctx, subject, predicate, 0)
+
+	for target := range chanTargets {
+		target.Free()
+	}
+
+	if err = <-chanErr; err != context.Canceled {
+		t.Fatalf("Expected cancellation error from FindTargetsCtx, got %v", err)
+	}
+
+	query, err := NewQuery(world, "sparql", "select ?p ?o where { <http://purl.org/net/dajobe/> ?p ?o}")
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	chanQueryResultItems, chanErr, err := model.ExecuteQueryToResultsChannelCtx(ctx, &query, 0)
+	if err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+
+	<-chanQueryResultItems
+	cancel()
+
+	for range chanQueryResultItems {
+	}
+
+	if err = <-chanErr; err != context.Canceled {
+		t.Fatalf("Expected cancellation error from ExecuteQueryToResultsChannelCtx, got %v", err)
+	}
+}
+
+//Test_RangeOverStatementsTargetsAndRows tests the following sequence:
+//	- Parsing RDFXML into a model
+//	- Ranging over the statements in the model, including breaking out of the loop
+//	- Ranging over the targets of a subject + predicate pair
+//	- Ranging over the rows of a SPARQL query
+func Test_RangeOverStatementsTargetsAndRows(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var uri *Uri
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	if uri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uri.Free()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if err = parser.ParseIntoModel(uri, nil, model); err != nil {
+		t.Fatalf("Failed to parse uri into model: %s", err.Error())
+	}
+
+	count := 0
+	for statement, err := range model.Statements(nil) {
+		if err != nil {
+			t.Fatalf("Failed to iterate statements: %s", err.Error())
+		}
+		statementString, _ := statement.ToString()
+		fmt.Printf("Statement: %s\n", statementString)
+		statement.Free()
+		count++
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected 3 statements, got %d", count)
+	}
+
+	// breaking out of the loop frees the librdf stream
+	for statement, err := range model.Statements(nil) {
+		if err != nil {
+			t.Fatalf("Failed to iterate statements: %s", err.Error())
+		}
+		statement.Free()
+		break
+	}
+
+	subject, err := NewNodeFromUriString(world, "http://purl.org/net/dajobe/")
+	if err != nil {
+		t.Fatalf("Failed to create subject node: %s", err.Error())
+	}
+	defer subject.Free()
+
+	predicate, err := NewNodeFromUriString(world, "http://purl.org/dc/elements/1.1/title")
+	if err != nil {
+		t.Fatalf("Failed to create predicate node: %s", err.Error())
+	}
+	defer predicate.Free()
+
+	count = 0
+	for target, err := range model.Targets(subject, predicate) {
+		if err != nil {
+			t.Fatalf("Failed to iterate targets: %s", err.Error())
+		}
+		fmt.Printf("Target: %s\n", target.String())
+		target.Free()
+		count++
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected 1 target, got %d", count)
+	}
+
+	query, err := NewQuery(world, "sparql", "select ?p ?o where { <http://purl.org/net/dajobe/> ?p ?o}")
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+
+	results, err := model.ExecuteQuery(&query)
+	if err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+	defer results.Free()
+
+	count = 0
+	for row, err := range results.Rows() {
+		if err != nil {
+			t.Fatalf("Failed to iterate query results: %s", err.Error())
+		}
+		for _, pair := range row.NameNodePairs {
+			fmt.Printf("%s: %s\n", pair.Name, pair.Node.String())
+		}
+		count++
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected 3 rows, got %d", count)
+	}
+}
+
+//Test_ModelArcNavigation tests the following sequence:
+//	- Parsing RDFXML into a model
+//	- Walking forwards from a subject through its targets and outgoing arcs
+//	- Walking backwards from an object through its sources and incoming arcs
+//	- Finding the arcs between a subject and an object
+func Test_ModelArcNavigation(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var uri *Uri
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	if uri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uri.Free()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if err = parser.ParseIntoModel(uri, nil, model); err != nil {
+		t.Fatalf("Failed to parse uri into model: %s", err.Error())
+	}
+
+	subject, err := NewNodeFromUriString(world, "http://purl.org/net/dajobe/")
+	if err != nil {
+		t.Fatalf("Failed to create subject node: %s", err.Error())
+	}
+	defer subject.Free()
+
+	predicate, err := NewNodeFromUriString(world, "http://purl.org/dc/elements/1.1/creator")
+	if err != nil {
+		t.Fatalf("Failed to create predicate node: %s", err.Error())
+	}
+	defer predicate.Free()
+
+	object, err := NewNodeFromLiteral(world, "Dave Beckett")
+	if err != nil {
+		t.Fatalf("Failed to create object node: %s", err.Error())
+	}
+	defer object.Free()
+
+	// walk forwards from the subject
+	target := model.GetTarget(subject, predicate)
+	if target == nil || !target.Equals(object) {
+		t.Fatalf("Expected GetTarget to return %s, got %v", object, target)
+	}
+	target.Free()
+
+	arcCount := 0
+	for arc := range model.ArcsOut(subject, 0) {
+		fmt.Printf("Arc out: %s\n", arc)
+		arc.Free()
+		arcCount++
+	}
+	if arcCount != 3 {
+		t.Fatalf("Expected 3 arcs out of subject, got %d", arcCount)
+	}
+
+	if !model.HasArcOut(subject, predicate) {
+		t.Fatalf("Expected subject to have an arc out for %s", predicate)
+	}
+
+	// walk backwards from the object
+	source := model.GetSource(predicate, object)
+	if source == nil || !source.Equals(subject) {
+		t.Fatalf("Expected GetSource to return %s, got %v", subject, source)
+	}
+	source.Free()
+
+	sourceCount := 0
+	for source := range model.FindSources(predicate, object, 0) {
+		source.Free()
+		sourceCount++
+	}
+	if sourceCount != 1 {
+		t.Fatalf("Expected 1 source, got %d", sourceCount)
+	}
+
+	arc := model.GetArc(subject, object)
+	if arc == nil || !arc.Equals(predicate) {
+		t.Fatalf("Expected GetArc to return %s, got %v", predicate, arc)
+	}
+	arc.Free()
+
+	arcCount = 0
+	for arc := range model.FindArcs(subject, object, 0) {
+		arc.Free()
+		arcCount++
+	}
+	if arcCount != 1 {
+		t.Fatalf("Expected 1 arc between subject and object, got %d", arcCount)
+	}
+
+	arcCount = 0
+	for arc := range model.ArcsIn(object, 0) {
+		arc.Free()
+		arcCount++
+	}
+	if arcCount != 1 {
+		t.Fatalf("Expected 1 arc into object, got %d", arcCount)
+	}
+
+	if !model.HasArcIn(object, predicate) {
+		t.Fatalf("Expected object to have an arc in for %s", predicate)
+	}
+
+	if model.HasArcIn(subject, predicate) {
+		t.Fatalf("Expected subject to have no arc in for %s", predicate)
+	}
+
+	if model.GetTarget(object, predicate) != nil {
+		t.Fatalf("Expected GetTarget to return nil for a literal source")
+	}
+}
+
+//Test_QueryLimitOffsetAndReuse tests the following sequence:
+//	- Parsing RDFXML into a model
+//	- Creating a query with relative IRIs resolved against a base URI
+//	- Paging through the results by executing the same query with a limit and changing offsets
+//	- Executing the query again while results from an earlier execution are live
+//	- Reading results after the query that produced them has been freed
+func Test_QueryLimitOffsetAndReuse(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var uri *Uri
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	if uri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uri.Free()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if err = parser.ParseIntoModel(uri, nil, model); err != nil {
+		t.Fatalf("Failed to parse uri into model: %s", err.Error())
+	}
+
+	// relative IRIs in the query are resolved against the base URI
+	var baseUri *Uri
+	if baseUri, err = NewUri(world, "http://purl.org/net/"); err != nil {
+		t.Fatalf("Failed to create base URI: %s", err.Error())
+	}
+	defer baseUri.Free()
+
+	query, err := NewQueryWithBaseUri(world, "sparql", "select ?p ?o where { <dajobe/> ?p ?o } order by ?p", baseUri)
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+	defer query.Free()
+
+	if err = query.SetLimit(2); err != nil {
+		t.Fatalf("Failed to set query limit: %s", err.Error())
+	}
+
+	// page through the results, executing the same prepared query each time
+	rowCount := 0
+	for offset := 0; offset < 4; offset += 2 {
+		if err = query.SetOffset(offset); err != nil {
+			t.Fatalf("Failed to set query offset: %s", err.Error())
+		}
+
+		results, err := model.ExecuteQuery(&query)
+		if err != nil {
+			t.Fatalf("Error executing query: %s", err.Error())
+		}
+
+		pageCount := 0
+		for row, err := range results.Rows() {
+			if err != nil {
+				t.Fatalf("Failed to iterate query results: %s", err.Error())
+			}
+			for _, pair := range row.NameNodePairs {
+				fmt.Printf("offset %d, %s: %s\n", offset, pair.Name, pair.Node.String())
+			}
+			pageCount++
+		}
+		results.Free()
+
+		if pageCount > 2 {
+			t.Fatalf("Expected at most 2 rows per page, got %d", pageCount)
+		}
+		rowCount += pageCount
+	}
+
+	if rowCount != 3 {
+		t.Fatalf("Expected 3 rows across all pages, got %d", rowCount)
+	}
+
+	if query.GetLimit() != 2 || query.GetOffset() != 2 {
+		t.Fatalf("Expected limit 2 and offset 2, got %d and %d", query.GetLimit(), query.GetOffset())
+	}
+
+	// the query can be executed again before the results of the previous execution are freed
+	staleResults, err := model.ExecuteQuery(&query)
+	if err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+
+	results, err := model.ExecuteQuery(&query)
+	if err != nil {
+		t.Fatalf("Error executing a query with live results: %s", err.Error())
+	}
+
+	if query.GetLimit() != 2 || query.GetOffset() != 2 {
+		t.Fatalf("Expected limit 2 and offset 2 after executing again, got %d and %d", query.GetLimit(), query.GetOffset())
+	}
+
+	// freeing the query keeps its librdf queries alive until the results are freed
+	query.Free()
+
+	for _, queryResults := range []*QueryResults{staleResults, results} {
+		rowCount = 0
+		for _, err := range queryResults.Rows() {
+			if err != nil {
+				t.Fatalf("Failed to iterate query results: %s", err.Error())
+			}
+			rowCount++
+		}
+		queryResults.Free()
+
+		if rowCount != 1 {
+			t.Fatalf("Expected 1 row after the query was freed, got %d", rowCount)
+		}
+	}
+
+	if _, err = model.ExecuteQuery(&query); !errors.Is(err, ErrFreed) {
+		t.Fatalf("Expected ErrFreed executing a freed query, got %v", err)
+	}
+}
+
+//Test_QueryResultForms tests the following sequence:
+//	- Parsing RDFXML into a model
+//	- Reading the binding names from a SELECT query
+//	- Reading the boolean result of an ASK query
+//	- Reading the statements of a CONSTRUCT query
+//	- Reading a SELECT query without matches through a results channel
+func Test_QueryResultForms(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var uri *Uri
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	if uri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uri.Free()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if err = parser.ParseIntoModel(uri, nil, model); err != nil {
+		t.Fatalf("Failed to parse uri into model: %s", err.Error())
+	}
+
+	// SELECT
+	selectQuery, err := NewQuery(world, "sparql", "select ?p ?o where { <http://purl.org/net/dajobe/> ?p ?o }")
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+	defer selectQuery.Free()
+
+	results, err := model.ExecuteQuery(&selectQuery)
+	if err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+
+	if !results.IsBindings() || results.IsBoolean() || results.IsGraph() {
+		t.Fatalf("Expected SELECT query to return bindings")
+	}
+
+	names, err := results.BindingNames()
+	if err != nil {
+		t.Fatalf("Failed to read binding names: %s", err.Error())
+	}
+	if strings.Join(names, ",") != "p,o" {
+		t.Fatalf("Expected binding names p,o, got %v", names)
+	}
+	results.Free()
+
+	// ASK
+	askQuery, err := NewQuery(world, "sparql", "ask { <http://purl.org/net/dajobe/> <http://purl.org/dc/elements/1.1/creator> \"Dave Beckett\" }")
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+	defer askQuery.Free()
+
+	if results, err = model.ExecuteQuery(&askQuery); err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+
+	if !results.IsBoolean() {
+		t.Fatalf("Expected ASK query to return a boolean")
+	}
+
+	var answer bool
+	if answer, err = results.Boolean(); err != nil {
+		t.Fatalf("Failed to read boolean result: %s", err.Error())
+	}
+	if !answer {
+		t.Fatalf("Expected ASK query to return true")
+	}
+	results.Free()
+
+	// CONSTRUCT
+	constructQuery, err := NewQuery(world, "sparql", "construct { ?o <http://example.org/describes> ?s } where { ?s ?p ?o }")
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+	defer constructQuery.Free()
+
+	if results, err = model.ExecuteQuery(&constructQuery); err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+
+	if !results.IsGraph() {
+		t.Fatalf("Expected CONSTRUCT query to return a graph")
+	}
+
+	statementCount := 0
+	for statement, err := range results.Statements() {
+		if err != nil {
+			t.Fatalf("Failed to iterate constructed statements: %s", err.Error())
+		}
+		statementString, _ := statement.ToString()
+		fmt.Printf("Constructed: %s\n", statementString)
+		statement.Free()
+		statementCount++
+	}
+	results.Free()
+
+	if statementCount != 3 {
+		t.Fatalf("Expected 3 constructed statements, got %d", statementCount)
+	}
+
+	// a SELECT query that matches nothing produces a closed, empty channel
+	emptyQuery, err := NewQuery(world, "sparql", "select ?o where { <http://example.org/nothing> ?p ?o }")
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+	defer emptyQuery.Free()
+
+	chanQueryResultItems, err := model.ExecuteQueryToResultsChannel(&emptyQuery, 0)
+	if err != nil {
+		t.Fatalf("Expected no error for a query without matches, got %s", err.Error())
+	}
+
+	for range chanQueryResultItems {
+		t.Fatalf("Expected no results")
+	}
+}
+
+//Test_ParameterizedQuery tests the following sequence:
+//	- Parsing RDFXML into a model
+//	- Binding nodes and Go values to the variables of a parameterized query
+//	- Executing the resulting query
+//	- Binding projected and ordered variables and executing the query
+//	- Checking that bound values are escaped and that invalid bindings are reported
+func Test_ParameterizedQuery(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var uri *Uri
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	if uri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uri.Free()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if err = parser.ParseIntoModel(uri, nil, model); err != nil {
+		t.Fatalf("Failed to parse uri into model: %s", err.Error())
+	}
+
+	template := `# ?subject in a comment is not a placeholder
+		select ?p where { ?subject ?p $object . FILTER(?p != <urn:?subject>) }`
+
+	parameterizedQuery, err := NewParameterizedQuery(world, "sparql", template)
+	if err != nil {
+		t.Fatalf("Failed to create parameterized query: %s", err.Error())
+	}
+
+	if variables := strings.Join(parameterizedQuery.Variables(), ","); variables != "p,subject,object" {
+		t.Fatalf("Expected variables p,subject,object, got %s", variables)
+	}
+
+	subject, err := NewNodeFromUriString(world, "http://purl.org/net/dajobe/")
+	if err != nil {
+		t.Fatalf("Failed to create subject node: %s", err.Error())
+	}
+	defer subject.Free()
+
+	if err = parameterizedQuery.Bind("subject", subject); err != nil {
+		t.Fatalf("Failed to bind subject: %s", err.Error())
+	}
+
+	if err = parameterizedQuery.BindValue("?object", "Dave Beckett"); err != nil {
+		t.Fatalf("Failed to bind object: %s", err.Error())
+	}
+
+	fmt.Printf("Query: %s\n", parameterizedQuery.QueryString())
+
+	expected := `# ?subject in a comment is not a placeholder
+		select ?p where { <http://purl.org/net/dajobe/> ?p "Dave Beckett" . FILTER(?p != <urn:?subject>) }`
+	if queryString := parameterizedQuery.QueryString(); queryString != expected {
+		t.Fatalf("Unexpected query text: %s", queryString)
+	}
+
+	query, err := parameterizedQuery.Query()
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+	defer query.Free()
+
+	results, err := model.ExecuteQuery(&query)
+	if err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+	defer results.Free()
+
+	rowCount := 0
+	for row, err := range results.Rows() {
+		if err != nil {
+			t.Fatalf("Failed to iterate query results: %s", err.Error())
+		}
+		fmt.Printf("p: %s\n", row.NameNodePairs[0].Node.String())
+		rowCount++
+	}
+
+	if rowCount != 1 {
+		t.Fatalf("Expected 1 row, got %d", rowCount)
+	}
+
+	// variables that name results are left as variables so that the query remains valid
+	projectedQuery, err := NewParameterizedQuery(world, "sparql", "select ?subject ?p where { ?subject ?p ?object } order by ?subject ?p")
+	if err != nil {
+		t.Fatalf("Failed to create parameterized query: %s", err.Error())
+	}
+
+	if err = projectedQuery.Bind("subject", subject); err != nil {
+		t.Fatalf("Failed to bind subject: %s", err.Error())
+	}
+
+	if err = projectedQuery.BindValue("object", "Dave Beckett"); err != nil {
+		t.Fatalf("Failed to bind object: %s", err.Error())
+	}
+
+	expected = `select ?subject ?p where { <http://purl.org/net/dajobe/> ?p "Dave Beckett" } order by ?subject ?p`
+	if queryString := projectedQuery.QueryString(); queryString != expected {
+		t.Fatalf("Unexpected query text: %s", queryString)
+	}
+
+	projected, err := projectedQuery.Query()
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+	defer projected.Free()
+
+	projectedResults, err := model.ExecuteQuery(&projected)
+	if err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+	defer projectedResults.Free()
+
+	rowCount = 0
+	for _, err := range projectedResults.Rows() {
+		if err != nil {
+			t.Fatalf("Failed to iterate query results: %s", err.Error())
+		}
+		rowCount++
+	}
+
+	if rowCount != 1 {
+		t.Fatalf("Expected 1 row, got %d", rowCount)
+	}
+
+	groupedQuery, err := NewParameterizedQuery(world, "sparql", "select ?p (count(?object) as ?count) where { ?subject ?p ?object } group by ?p")
+	if err != nil {
+		t.Fatalf("Failed to create parameterized query: %s", err.Error())
+	}
+
+	if err = groupedQuery.Bind("p", subject); err != nil {
+		t.Fatalf("Failed to bind p: %s", err.Error())
+	}
+
+	expected = "select ?p (count(?object) as ?count) where { ?subject <http://purl.org/net/dajobe/> ?object } group by ?p"
+	if queryString := groupedQuery.QueryString(); queryString != expected {
+		t.Fatalf("Unexpected query text: %s", queryString)
+	}
+
+	if err = groupedQuery.BindValue("count", 1); err == nil {
+		t.Fatalf("Expected an error binding a variable that is only assigned by AS")
+	}
+
+	// values that would break out of a string are escaped
+	if err = parameterizedQuery.BindValue("object", "\" } . ?s ?p ?o . { \""); err != nil {
+		t.Fatalf("Failed to bind object: %s", err.Error())
+	}
+	if !strings.Contains(parameterizedQuery.QueryString(), `"\" } . ?s ?p ?o . { \""`) {
+		t.Fatalf("Expected bound value to be escaped: %s", parameterizedQuery.QueryString())
+	}
+
+	blank, err := NewBlankNode(world)
+	if err != nil {
+		t.Fatalf("Failed to create blank node: %s", err.Error())
+	}
+	defer blank.Free()
+
+	if err = parameterizedQuery.Bind("subject", blank); err == nil {
+		t.Fatalf("Expected an error binding a blank node")
+	}
+
+	if err = parameterizedQuery.BindValue("missing", 1); err == nil {
+		t.Fatalf("Expected an error binding a variable that is not in the query")
+	}
+}
+
+//Test_DecodeQueryResultRows tests the following sequence:
+//	- Parsing N-Triples into a model
+//	- Executing a SPARQL query with an optional variable
+//	- Reading variables from each row with Get
+//	- Decoding each row into a tagged struct
+func Test_DecodeQueryResultRows(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "ntriples", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	data := `<http://example.org/alice> <http://xmlns.com/foaf/0.1/name> "Alice" .
+<http://example.org/alice> <http://xmlns.com/foaf/0.1/age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.org/bob> <http://xmlns.com/foaf/0.1/name> "Bob" .
+`
+
+	if err = parser.ParseStringIntoModel(data, nil, model); err != nil {
+		t.Fatalf("Failed to parse string into model: %s", err.Error())
+	}
+
+	query, err := NewQuery(world, "sparql", `select ?person ?name ?age where {
+		?person <http://xmlns.com/foaf/0.1/name> ?name .
+		OPTIONAL { ?person <http://xmlns.com/foaf/0.1/age> ?age }
+	} order by ?name`)
+	if err != nil {
+		t.Fatalf("Error creating query :%s", err.Error())
+	}
+	defer query.Free()
+
+	results, err := model.ExecuteQuery(&query)
+	if err != nil {
+		t.Fatalf("Error executing query: %s", err.Error())
+	}
+	defer results.Free()
+
+	type person struct {
+		Id    string `rdf:"?person"`
+		Node  *Node  `rdf:"?person"`
+		Name  string `rdf:"?name"`
+		Age   *int   `rdf:"?age"`
+		Other string
+	}
+
+	var people []person
+	for row, err := range results.Rows() {
+		if err != nil {
+			t.Fatalf("Failed to iterate query results: %s", err.Error())
+		}
+
+		if row.Get("?name") == nil || row.Get("name") == nil {
+			t.Fatalf("Expected ?name to be bound")
+		}
+
+		var p person
+		if err = row.Decode(&p); err != nil {
+			t.Fatalf("Failed to decode row: %s", err.Error())
+		}
+		fmt.Printf("Decoded: %+v\n", p)
+		people = append(people, p)
+	}
+
+	if len(people) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(people))
+	}
+
+	if people[0].Id != "http://example.org/alice" || people[0].Name != "Alice" || people[0].Age == nil || *people[0].Age != 42 {
+		t.Fatalf("Unexpected first row: %+v", people[0])
+	}
+	if people[0].Node == nil || !people[0].Node.IsResource() {
+		t.Fatalf("Expected the person node to be decoded")
+	}
+	people[0].Node.Free()
+
+	if people[1].Name != "Bob" || people[1].Age != nil {
+		t.Fatalf("Unexpected second row: %+v", people[1])
+	}
+	people[1].Node.Free()
+
+	var notStruct int
+	if err = (&QueryResultItem{}).Decode(&notStruct); err == nil {
+		t.Fatalf("Expected an error decoding into a non-struct")
+	}
+}
+
+type testAddress struct {
+	Street string `rdf:"http://schema.org/streetAddress"`
+	City   string `rdf:"http://schema.org/addressLocality"`
+}
+
+type testPerson struct {
+	Name     string       `rdf:"http://xmlns.com/foaf/0.1/name,lang=en"`
+	Age      int          `rdf:"http://xmlns.com/foaf/0.1/age"`
+	Height   float64      `rdf:"http://example.org/height,omitempty"`
+	Born     time.Time    `rdf:"http://example.org/born,datatype=<http://www.w3.org/2001/XMLSchema#dateTime>"`
+	Homepage string       `rdf:"http://xmlns.com/foaf/0.1/homepage,iri"`
+	Nicks    []string     `rdf:"http://xmlns.com/foaf/0.1/nick"`
+	Scores   []int        `rdf:"http://example.org/scores,list"`
+	Address  *testAddress `rdf:"http://schema.org/address"`
+	Ignored  string       `rdf:"-"`
+	Untagged string
+}
+
+type testChain struct {
+	Label string     `rdf:"http://www.w3.org/2000/01/rdf-schema#label"`
+	Next  *testChain `rdf:"http://example.org/next"`
+}
+
+//Test_MarshalAndUnmarshalStructs tests the following sequence:
+//	- Marshalling a tagged struct, including slices, lists and a nested struct, into a model
+//	- Checking the statements that were written
+//	- Unmarshalling the statements back into a struct
+//	- Reading only the literals with the language given in a tag
+//	- Reporting cycles in structs and in the model as errors
+func Test_MarshalAndUnmarshalStructs(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	subject, err := NewNodeFromUriString(world, "http://example.org/alice")
+	if err != nil {
+		t.Fatalf("Failed to create subject: %s", err.Error())
+	}
+	defer subject.Free()
+
+	born := time.Date(1980, time.March, 4, 5, 6, 7, 0, time.UTC)
+	alice := testPerson{
+		Name:     "Alice",
+		Age:      42,
+		Born:     born,
+		Homepage: "http://example.org/~alice",
+		Nicks:    []string{"al", "ally"},
+		Scores:   []int{3, 1, 2},
+		Address:  &testAddress{Street: "1 Main Street", City: "Springfield"},
+		Ignored:  "not written",
+		Untagged: "not written",
+	}
+
+	if err = Marshal(model, subject, &alice); err != nil {
+		t.Fatalf("Failed to marshal struct: %s", err.Error())
+	}
+
+	fmt.Printf("Marshalled model: %s\n", model.ToString())
+
+	// name, age, born, homepage, 2 nicks, list head + 3 cells * 2, address + 2 address fields
+	if size, _ := model.Size(); size != 16 {
+		t.Fatalf("Expected 16 statements, got %d", size)
+	}
+
+	namePredicate, _ := NewNodeFromUriString(world, "http://xmlns.com/foaf/0.1/name")
+	defer namePredicate.Free()
+
+	name := model.GetTarget(subject, namePredicate)
+	if name == nil || name.GetLiteralValueLanguage() != "en" {
+		t.Fatalf("Expected name to be written with a language tag")
+	}
+	name.Free()
+
+	var decoded testPerson
+	if err = Unmarshal(model, subject, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal struct: %s", err.Error())
+	}
+
+	fmt.Printf("Unmarshalled: %+v\n", decoded)
+
+	if decoded.Name != alice.Name || decoded.Age != alice.Age || !decoded.Born.Equal(born) || decoded.Homepage != alice.Homepage {
+		t.Fatalf("Unexpected unmarshalled values: %+v", decoded)
+	}
+
+	if strings.Join(decoded.Nicks, ",") != "al,ally" {
+		t.Fatalf("Expected nicks al,ally, got %v", decoded.Nicks)
+	}
+
+	if fmt.Sprint(decoded.Scores) != "[3 1 2]" {
+		t.Fatalf("Expected list order to be kept, got %v", decoded.Scores)
+	}
+
+	if decoded.Address == nil || *decoded.Address != *alice.Address {
+		t.Fatalf("Unexpected unmarshalled address: %+v", decoded.Address)
+	}
+
+	if decoded.Ignored != "" || decoded.Untagged != "" || decoded.Height != 0 {
+		t.Fatalf("Expected ignored fields to be left empty: %+v", decoded)
+	}
+
+	if err = Marshal(model, subject, "not a struct"); err == nil {
+		t.Fatalf("Expected an error marshalling a non-struct")
+	}
+
+	// only literals with the language given in the tag are read
+	var frenchName *Node
+	if frenchName, err = NewNodeFromLiteralWithLanguage(world, "Alicia", "fr"); err != nil {
+		t.Fatalf("Failed to create literal node: %s", err.Error())
+	}
+	defer frenchName.Free()
+
+	if err = model.addTriple(subject, namePredicate, frenchName); err != nil {
+		t.Fatalf("Failed to add statement: %s", err.Error())
+	}
+
+	var english testPerson
+	if err = Unmarshal(model, subject, &english); err != nil {
+		t.Fatalf("Failed to unmarshal struct: %s", err.Error())
+	}
+
+	if english.Name != "Alice" {
+		t.Fatalf("Expected the name tagged en to be read, got %q", english.Name)
+	}
+
+	// cycles are reported rather than overflowing the stack
+	var loop *Node
+	if loop, err = NewBlankNodeFromId(world, "loop"); err != nil {
+		t.Fatalf("Failed to create blank node: %s", err.Error())
+	}
+	defer loop.Free()
+
+	chain := &testChain{Label: "loop"}
+	chain.Next = chain
+
+	if err = Marshal(model, loop, chain); err == nil {
+		t.Fatalf("Expected an error marshalling a struct that refers to itself")
+	}
+
+	var nextPredicate *Node
+	if nextPredicate, err = NewNodeFromUriString(world, "http://example.org/next"); err != nil {
+		t.Fatalf("Failed to create predicate node: %s", err.Error())
+	}
+	defer nextPredicate.Free()
+
+	if err = model.addTriple(loop, nextPredicate, loop); err != nil {
+		t.Fatalf("Failed to add statement: %s", err.Error())
+	}
+
+	var decodedChain testChain
+	if err = Unmarshal(model, loop, &decodedChain); err == nil {
+		t.Fatalf("Expected an error unmarshalling a blank node that refers to itself")
+	}
+	fmt.Printf("Cycle error: %s\n", err.Error())
+}
+
+//Test_ParseReaderIntoModel tests the following sequence:
+//	- Parsing RDFXML from an io.Reader into a model
+//	- Parsing N-Triples larger than a single chunk from an io.Reader
+//	- Checking that invalid data read from an io.Reader is reported as an error
+func Test_ParseReaderIntoModel(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+	var baseUri *Uri
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if baseUri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer baseUri.Free()
+
+	file, err := os.Open("testdata/dc.rdf")
+	if err != nil {
+		t.Fatalf("Failed to open test data: %s", err.Error())
+	}
+	defer file.Close()
+
+	if err = parser.ParseReaderIntoModel(file, baseUri, model); err != nil {
+		t.Fatalf("Failed to parse reader into model: %s", err.Error())
+	}
+
+	if size, _ := model.Size(); size != 3 {
+		t.Fatalf("Expected 3 statements from RDF/XML, got %d", size)
+	}
+
+	// a document larger than a single chunk is parsed in pieces
+	var builder strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&builder, "<http://example.org/item/%d> <http://purl.org/dc/elements/1.1/title> \"Item %d\" .\n", i, i)
+	}
+
+	var ntriplesParser *Parser
+	if ntriplesParser, err = NewParser(world, "ntriples", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer ntriplesParser.Free()
+
+	if err = ntriplesParser.ParseReaderIntoModel(strings.NewReader(builder.String()), nil, model); err != nil {
+		t.Fatalf("Failed to parse reader into model: %s", err.Error())
+	}
+
+	if size, _ := model.Size(); size != 5003 {
+		t.Fatalf("Expected 5003 statements, got %d", size)
+	}
+
+	if err = ntriplesParser.ParseReaderIntoModel(strings.NewReader("<http://example.org/broken> ."), nil, model); err == nil {
+		t.Fatalf("Expected an error parsing invalid N-Triples")
+	}
+}
+
+//Test_ParseAsStream tests the following sequence:
+//	- Parsing RDFXML from a URI as a stream of statements
+//	- Filtering statements parsed from a string without building a model
+//	- Breaking out of a loop over a parsed stream
+func Test_ParseAsStream(t *testing.T) {
+	var err error
+	var uri *Uri
+	var parser *Parser
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	if uri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uri.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	count := 0
+	for statement, err := range parser.ParseAsStream(uri, nil) {
+		if err != nil {
+			t.Fatalf("Failed to parse uri as stream: %s", err.Error())
+		}
+		statementString, _ := statement.ToString()
+		fmt.Printf("Parsed: %s\n", statementString)
+		statement.Free()
+		count++
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected 3 statements, got %d", count)
+	}
+
+	var ntriplesParser *Parser
+	if ntriplesParser, err = NewParser(world, "ntriples", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer ntriplesParser.Free()
+
+	data := `<http://example.org/a> <http://purl.org/dc/elements/1.1/title> "A" .
+<http://example.org/b> <http://purl.org/dc/elements/1.1/title> "B" .
+<http://example.org/c> <http://purl.org/dc/elements/1.1/creator> "C" .
+`
+
+	// filter the parsed statements without building a model
+	titles := []string{}
+	for statement, err := range ntriplesParser.ParseStringAsStream(data, nil) {
+		if err != nil {
+			t.Fatalf("Failed to parse string as stream: %s", err.Error())
+		}
+		if statement.GetPredicate().GetUriString() == "http://purl.org/dc/elements/1.1/title" {
+			titles = append(titles, statement.GetObject().GetLiteralValue())
+		}
+		statement.Free()
+	}
+
+	if strings.Join(titles, ",") != "A,B" {
+		t.Fatalf("Expected titles A,B, got %v", titles)
+	}
+
+	// breaking out of the loop frees the stream
+	for statement := range ntriplesParser.ParseStringAsStream(data, nil) {
+		statement.Free()
+		break
+	}
+}
+
+//failingWriter is an io.Writer that fails after writing limit bytes
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errors.New("writer is full")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+//Test_SerializeToWriterAndFile tests the following sequence:
+//	- Parsing RDFXML into a model
+//	- Serializing the model to an io.Writer and to a file
+//	- Serializing statements parsed as a stream without building a model
+//	- Checking that errors from the io.Writer are returned
+func Test_SerializeToWriterAndFile(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var uri *Uri
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+	var serializer *Serializer
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	if uri, err = NewUri(world, testLocalUriFile); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uri.Free()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "rdfxml", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if err = parser.ParseIntoModel(uri, nil, model); err != nil {
+		t.Fatalf("Failed to parse uri into model: %s", err.Error())
+	}
+
+	if serializer, err = NewSerializer(world, "ntriples", "", nil); err != nil {
+		t.Fatalf("Failed to create serializer: %s", err.Error())
+	}
+	defer serializer.Free()
+
+	var buffer bytes.Buffer
+	if err = serializer.SerializeModelToWriter(&buffer, nil, model); err != nil {
+		t.Fatalf("Failed to serialize model to writer: %s", err.Error())
+	}
+
+	fmt.Printf("Serialized model:\n%s", buffer.String())
+
+	if lines := strings.Count(buffer.String(), "\n"); lines != 3 {
+		t.Fatalf("Expected 3 lines of N-Triples, got %d", lines)
+	}
+
+	fileName := "./testoutput/Test_SerializeToWriterAndFile.nt"
+	if err = serializer.SerializeModelToFile(fileName, nil, model); err != nil {
+		t.Fatalf("Failed to serialize model to file: %s", err.Error())
+	}
+	defer os.Remove(fileName)
+
+	fileContents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Failed to read serialized file: %s", err.Error())
+	}
+	if string(fileContents) != buffer.String() {
+		t.Fatalf("Expected file contents to match writer output")
+	}
+
+	// statements can be passed from a parser to a serializer without building a model
+	var turtleSerializer *Serializer
+	if turtleSerializer, err = NewSerializer(world, "turtle", "", nil); err != nil {
+		t.Fatalf("Failed to create serializer: %s", err.Error())
+	}
+	defer turtleSerializer.Free()
+
+	buffer.Reset()
+	if err = turtleSerializer.SerializeStreamToWriter(&buffer, nil, parser.ParseAsStream(uri, nil)); err != nil {
+		t.Fatalf("Failed to serialize stream to writer: %s", err.Error())
+	}
+
+	fmt.Printf("Serialized stream:\n%s", buffer.String())
+
+	if !strings.Contains(buffer.String(), "Dave Beckett") {
+		t.Fatalf("Expected serialized stream to contain the parsed statements")
+	}
+
+	// errors from the writer are returned
+	if err = serializer.SerializeModelToWriter(&failingWriter{limit: 10}, nil, model); err == nil || err.Error() != "writer is full" {
+		t.Fatalf("Expected the writer error to be returned, got %v", err)
+	}
+}
+
+//Test_SerializerNamespaces tests the following sequence:
+//	- Parsing turtle into a model and listing the namespaces seen by the parser
+//	- Copying the namespaces to a serializer and adding a namespace
+//	- Serializing the model and a statement stream using the namespace prefixes
+func Test_SerializerNamespaces(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+	var serializer *Serializer
+	var baseUri *Uri
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "turtle", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if baseUri, err = NewUri(world, "http://example.org/"); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer baseUri.Free()
+
+	data := `@prefix dc: <http://purl.org/dc/elements/1.1/> .
+@prefix ex: <http://example.org/things/> .
+ex:book dc:title "A Book" ; dc:creator "An Author" .
+`
+
+	if err = parser.ParseReaderIntoModel(strings.NewReader(data), baseUri, model); err != nil {
+		t.Fatalf("Failed to parse reader into model: %s", err.Error())
+	}
+
+	namespaces := parser.NamespacesSeen()
+	fmt.Printf("Namespaces seen: %v\n", namespaces)
+
+	if len(namespaces) != 2 || namespaces[0] != (Namespace{Prefix: "dc", Uri: "http://purl.org/dc/elements/1.1/"}) {
+		t.Fatalf("Expected the dc and ex namespaces to be seen, got %v", namespaces)
+	}
+
+	if serializer, err = NewSerializer(world, "turtle", "", nil); err != nil {
+		t.Fatalf("Failed to create serializer: %s", err.Error())
+	}
+	defer serializer.Free()
+
+	if err = serializer.CopyNamespaces(parser); err != nil {
+		t.Fatalf("Failed to copy namespaces: %s", err.Error())
+	}
+
+	var rdfUri *Uri
+	if rdfUri, err = NewUri(world, RdfNamespace); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer rdfUri.Free()
+
+	if err = serializer.SetNamespace("rdf", rdfUri); err != nil {
+		t.Fatalf("Failed to set namespace: %s", err.Error())
+	}
+
+	var output string
+	if output, err = serializer.SerializeModelToString(model, nil); err != nil {
+		t.Fatalf("Failed to serialize model: %s", err.Error())
+	}
+
+	fmt.Printf("Serialized with prefixes:\n%s", output)
+
+	if !strings.Contains(output, "@prefix dc: <http://purl.org/dc/elements/1.1/>") || !strings.Contains(output, "dc:title") {
+		t.Fatalf("Expected the dc prefix to be used in the output")
+	}
+
+	// namespaces also apply when serializing a statement stream
+	var buffer bytes.Buffer
+	if err = serializer.SerializeStreamToWriter(&buffer, nil, model.Statements(nil)); err != nil {
+		t.Fatalf("Failed to serialize stream: %s", err.Error())
+	}
+
+	if !strings.Contains(buffer.String(), "ex:book") {
+		t.Fatalf("Expected the ex prefix to be used in the stream output:\n%s", buffer.String())
+	}
+}
+
+//Test_ParseErrors tests the following sequence:
+//	- Parsing invalid turtle into a model
+//	- Checking that the failure is a *ParseError holding the line of each error
+//	- Checking the same errors are reported when parsing from a reader or as a stream
+//	- Checking that query syntax errors are also reported as a *ParseError
+//	- Checking that data which cannot be retrieved is reported as a *FetchError
+func Test_ParseErrors(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+	var baseUri *Uri
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "turtle", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if baseUri, err = NewUri(world, "http://example.org/"); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer baseUri.Free()
+
+	data := `@prefix dc: <http://purl.org/dc/elements/1.1/> .
+<http://example.org/a> dc:title "A" .
+<http://example.org/b> dc:title "B
+`
+
+	err = parser.ParseStringIntoModel(data, baseUri, model)
+
+	var parseError *ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatalf("Expected a *ParseError, got %v", err)
+	}
+
+	fmt.Printf("Parse error: %s\n", parseError.Error())
+	for _, message := range parseError.Messages {
+		fmt.Printf("  %s\n", message)
+	}
+
+	parseErrors := parseError.Errors()
+	if len(parseErrors) == 0 {
+		t.Fatalf("Expected the parse error to include the logged errors")
+	}
+
+	if parseErrors[0].Line != 3 || parseErrors[0].Facility != LogFacilityParser && parseErrors[0].Facility != LogFacilityRaptor {
+		t.Fatalf("Expected an error from the parser on line 3, got %+v", parseErrors[0])
+	}
+
+	// the same errors are reported when parsing from a reader or as a stream
+	err = parser.ParseReaderIntoModel(strings.NewReader(data), baseUri, model)
+	if !errors.As(err, &parseError) || len(parseError.Errors()) == 0 || parseError.Errors()[0].Line != 3 {
+		t.Fatalf("Expected a *ParseError with the line number from ParseReaderIntoModel, got %v", err)
+	}
+
+	var streamErr error
+	for statement, err := range parser.ParseStringAsStream(data, baseUri) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		statement.Free()
+	}
+	if !errors.As(streamErr, &parseError) {
+		t.Fatalf("Expected a *ParseError from ParseStringAsStream, got %v", streamErr)
+	}
+
+	// query syntax errors are also reported as a *ParseError
+	_, err = NewQuery(world, "sparql", "select ?s where { ?s ?p }")
+	if !errors.As(err, &parseError) {
+		t.Fatalf("Expected a *ParseError for an invalid query, got %v", err)
+	}
+	fmt.Printf("Query parse error: %s\n", err.Error())
+
+	// data that cannot be retrieved is reported as a *FetchError rather than a syntax error
+	var missingUri *Uri
+	if missingUri, err = NewUri(world, "file:./testdata/missing.ttl"); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer missingUri.Free()
+
+	err = parser.ParseIntoModel(missingUri, nil, model)
+
+	var fetchError *FetchError
+	if !errors.As(err, &fetchError) || !errors.Is(err, ErrFetch) || errors.Is(err, ErrSyntax) {
+		t.Fatalf("Expected a *FetchError parsing a missing file, got %v", err)
+	}
+	fmt.Printf("Fetch error: %s\n", err.Error())
+}
+
+//Test_SetLogger tests the following sequence:
+//	- Setting a logger before the world is opened
+//	- Parsing invalid turtle and checking the messages logged with their facility and location
+//	- Logging from several goroutines while the logger is replaced
+func Test_SetLogger(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+	var parser *Parser
+	var baseUri *Uri
+
+	var logOutput bytes.Buffer
+
+	world := NewWorld()
+
+	// the logger may be set before the world is opened
+	world.SetLogger(slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	if parser, err = NewParser(world, "turtle", ""); err != nil {
+		t.Fatalf("Failed to create parser: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if baseUri, err = NewUri(world, "http://example.org/"); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer baseUri.Free()
+
+	data := `<http://example.org/a> <http://purl.org/dc/elements/1.1/title> "A" .
+<http://example.org/b> <http://purl.org/dc/elements/1.1/title> "B
+`
+
+	// the failure is still reported as a ParseError while the messages also go to the logger
+	var parseError *ParseError
+	if err = parser.ParseStringIntoModel(data, baseUri, model); !errors.As(err, &parseError) {
+		t.Fatalf("Expected a *ParseError, got %v", err)
+	}
+
+	logged := logOutput.String()
+	fmt.Printf("Logged: %s", logged)
+
+	if !strings.Contains(logged, "level=ERROR") {
+		t.Fatalf("Expected the parse error to be logged at error level")
+	}
+
+	if !strings.Contains(logged, "facility=") || !strings.Contains(logged, "locator.line=") {
+		t.Fatalf("Expected the facility and location to be logged as attributes")
+	}
+
+	// messages may be logged from several goroutines while the logger is replaced
+	logOutput.Reset()
+
+	var lockedOutput lockedBuffer
+	world.SetLogger(slog.New(slog.NewTextHandler(&lockedOutput, nil)))
+
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer func() { done <- true }()
+
+			for j := 0; j < 100; j++ {
+				world.log.handle(LogMessage{Level: LogLevelWarn, Facility: LogFacilityStorage, Message: "concurrent"}, nil)
+			}
+		}()
+	}
+	world.SetLogger(slog.New(slog.NewTextHandler(&lockedOutput, nil)))
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+
+	if count := strings.Count(lockedOutput.String(), "level=WARN"); count != 400 {
+		t.Fatalf("Expected 400 messages to be logged, got %d", count)
+	}
+
+	if logOutput.Len() != 0 {
+		t.Fatalf("Expected no messages to be logged after the logger was replaced")
+	}
+}
+
+//Test_ConcurrentParseMessages tests the following sequence:
+//	- Parsing invalid turtle on its own to find the messages it logs
+//	- Parsing valid turtle and two sets of invalid turtle at the same time on separate goroutines sharing a world
+//	- Checking that each valid parse succeeds without picking up the errors of the invalid parses
+//	- Checking that each invalid parse reports only its own messages
+func Test_ConcurrentParseMessages(t *testing.T) {
+	var err error
+
+	var logOutput lockedBuffer
+
+	world := NewWorld()
+	world.SetLogger(slog.New(slog.NewTextHandler(&logOutput, nil)))
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	validData := `<http://example.org/a> <http://purl.org/dc/elements/1.1/title> "A" .
+<http://example.org/b> <http://purl.org/dc/elements/1.1/title> "B" .
+`
+
+	invalidData := `<http://example.org/a> <http://purl.org/dc/elements/1.1/title> "A" .
+<http://example.org/b> <http://purl.org/dc/elements/1.1/title> "B
+`
+
+	// parse parses data count times with a parser and model of its own, sending the result of each parse to results
+	parse := func(data string, baseUriString string, count int, results chan<- error) {
+		var err error
+		var storage *Storage
+		var model *Model
+		var parser *Parser
+		var baseUri *Uri
+
+		if storage, err = NewStorage(world, "memory", "test", ""); err != nil {
+			results <- err
+			return
+		}
+		defer storage.Free()
+
+		if model, err = NewModel(world, storage, ""); err != nil {
+			results <- err
+			return
+		}
+		defer model.Free()
+
+		if parser, err = NewParser(world, "turtle", ""); err != nil {
+			results <- err
+			return
+		}
+		defer parser.Free()
+
+		if baseUri, err = NewUri(world, baseUriString); err != nil {
+			results <- err
+			return
+		}
+		defer baseUri.Free()
+
+		for i := 0; i < count; i++ {
+			results <- parser.ParseStringIntoModel(data, baseUri, model)
+		}
+	}
+
+	// checkOwnMessages fails the test unless err is a *ParseError holding the messages expected for the data parsed from baseUriString
+	checkOwnMessages := func(err error, baseUriString string, expectedCount int) {
+		var parseError *ParseError
+		if !errors.As(err, &parseError) {
+			t.Fatalf("Expected a *ParseError for the invalid data, got %v", err)
+		}
+
+		if len(parseError.Messages) != expectedCount {
+			t.Fatalf("Expected %d messages for the invalid data, got %d: %v", expectedCount, len(parseError.Messages), parseError.Messages)
+		}
+
+		for _, message := range parseError.Messages {
+			if message.Uri != "" && message.Uri != baseUriString {
+				t.Fatalf("Expected only the messages for %s, got %s", baseUriString, message)
+			}
+		}
+	}
+
+	// the messages logged for the invalid data when it is parsed on its own
+	referenceResults := make(chan error, 1)
+	parse(invalidData, "http://example.org/reference/", 1, referenceResults)
+
+	var parseError *ParseError
+	if err = <-referenceResults; !errors.As(err, &parseError) || len(parseError.Errors()) == 0 {
+		t.Fatalf("Expected a *ParseError with logged errors for the invalid data, got %v", err)
+	}
+	expectedCount := len(parseError.Messages)
+
+	count := 50
+	validResults := make(chan error, count)
+	firstInvalidResults := make(chan error, count)
+	secondInvalidResults := make(chan error, count)
+
+	go parse(validData, "http://example.org/valid/", count, validResults)
+	go parse(invalidData, "http://example.org/first/", count, firstInvalidResults)
+	go parse(invalidData, "http://example.org/second/", count, secondInvalidResults)
+
+	for i := 0; i < count; i++ {
+		if err = <-validResults; err != nil {
+			t.Fatalf("Expected the valid data to parse without error, got %v", err)
+		}
+
+		checkOwnMessages(<-firstInvalidResults, "http://example.org/first/", expectedCount)
+		checkOwnMessages(<-secondInvalidResults, "http://example.org/second/", expectedCount)
+	}
+
+	if !strings.Contains(logOutput.String(), "level=ERROR") {
+		t.Fatalf("Expected the parse errors to also be logged")
+	}
+}
+
+//lockedBuffer is a bytes.Buffer that may be written from several goroutines
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+//Test_TypedErrors tests the following sequence:
+//	- Using a world that has not been opened
+//	- Passing nil arguments to a model
+//	- Using a transaction after it has ended
+//	- Distinguishing query syntax errors from results of the wrong form
+//	- Constructing parsers and serializers for unknown syntaxes
+//	- Using a model and a statement after they have been freed
+func Test_TypedErrors(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+	var statement *Statement
+
+	// a world that has not been opened cannot be used
+	closedWorld := NewWorld()
+
+	_, err = NewStorage(closedWorld, storageType, "test", "")
+
+	var storageError *StorageError
+	if !errors.As(err, &storageError) || !errors.Is(err, ErrWorldClosed) {
+		t.Fatalf("Expected a *StorageError for a closed world, got %v", err)
+	}
+	fmt.Printf("Storage error: %s\n", err.Error())
+
+	if _, err = NewUri(closedWorld, "http://example.org/"); !errors.Is(err, ErrWorldClosed) {
+		t.Fatalf("Expected ErrWorldClosed creating a URI in a closed world, got %v", err)
+	}
+
+	var nodeError *NodeError
+	if _, err = NewNodeFromLiteral(closedWorld, "literal"); !errors.As(err, &nodeError) || !errors.Is(err, ErrWorldClosed) {
+		t.Fatalf("Expected a *NodeError creating a literal node in a closed world, got %v", err)
+	}
+
+	if _, err = NewBlankNode(nil); !errors.As(err, &nodeError) || !errors.Is(err, ErrWorldClosed) {
+		t.Fatalf("Expected a *NodeError creating a blank node without a world, got %v", err)
+	}
+
+	var statementError *StatementError
+	if _, err = NewStatement(closedWorld); !errors.As(err, &statementError) || !errors.Is(err, ErrWorldClosed) {
+		t.Fatalf("Expected a *StatementError creating a statement in a closed world, got %v", err)
+	}
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	var worldError *WorldError
+	if err = world.Open(); !errors.As(err, &worldError) || !errors.Is(err, ErrWorldAlreadyOpen) {
+		t.Fatalf("Expected a *WorldError opening an open world, got %v", err)
+	}
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+
+	if statement, err = NewStatement(world); err != nil {
+		t.Fatalf("Failed to create statement: %s", err.Error())
+	}
+	defer statement.Free()
+
+	// nil arguments are reported rather than causing a panic
+	var modelError *ModelError
+	if err = model.AddStatement(nil); !errors.As(err, &modelError) || !errors.Is(err, ErrNilArgument) {
+		t.Fatalf("Expected a *ModelError for a nil statement, got %v", err)
+	}
+
+	for _, err = range model.Targets(nil, nil) {
+		break
+	}
+	if !errors.Is(err, ErrNilArgument) {
+		t.Fatalf("Expected ErrNilArgument iterating targets of nil nodes, got %v", err)
+	}
+
+	if model.GetTarget(nil, nil) != nil || model.HasArcOut(nil, nil) {
+		t.Fatalf("Expected no result for nil nodes")
+	}
+
+	// a transaction cannot be used once it has ended
+	var tx *Tx
+	if tx, err = model.Begin(); err != nil {
+		t.Fatalf("Failed to begin transaction: %s", err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit transaction: %s", err.Error())
+	}
+
+	var txError *TxError
+	if err = tx.Commit(); !errors.As(err, &txError) || !errors.Is(err, ErrTxDone) {
+		t.Fatalf("Expected a *TxError committing an ended transaction, got %v", err)
+	}
+
+	if err = tx.AddStatement(statement); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("Expected ErrTxDone adding to an ended transaction, got %v", err)
+	}
+
+	if err = tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("Expected ErrTxDone rolling back an ended transaction, got %v", err)
+	}
+
+	// query syntax errors and results of the wrong form are distinguished
+	_, err = NewQuery(world, "sparql", "select ?s where { ?s ?p }")
+
+	var queryError *QueryError
+	if !errors.As(err, &queryError) || !errors.Is(err, ErrSyntax) {
+		t.Fatalf("Expected a *QueryError wrapping a syntax error, got %v", err)
+	}
+	fmt.Printf("Query error: %s\n", err.Error())
+
+	query, err := NewQuery(world, "sparql", "select ?s where { ?s ?p ?o }")
+	if err != nil {
+		t.Fatalf("Failed to create query: %s", err.Error())
+	}
+	defer query.Free()
+
+	results, err := model.ExecuteQuery(&query)
+	if err != nil {
+		t.Fatalf("Failed to execute query: %s", err.Error())
+	}
+
+	if _, err = results.Boolean(); !errors.Is(err, ErrResultForm) {
+		t.Fatalf("Expected ErrResultForm reading a boolean from bindings, got %v", err)
+	}
+	results.Free()
+
+	// unknown syntaxes are reported by parsers and serializers
+	var parserError *ParserError
+	if _, err = NewParser(world, "no-such-syntax", ""); !errors.As(err, &parserError) || !errors.Is(err, ErrUnsupportedSyntax) {
+		t.Fatalf("Expected a *ParserError for an unknown syntax, got %v", err)
+	}
+
+	var serializerError *SerializerError
+	if _, err = NewSerializer(world, "no-such-syntax", "", nil); !errors.As(err, &serializerError) || !errors.Is(err, ErrUnsupportedSyntax) {
+		t.Fatalf("Expected a *SerializerError for an unknown syntax, got %v", err)
+	}
+
+	// a freed model reports ErrFreed
+	model.Free()
+
+	if err = model.AddStatement(statement); !errors.Is(err, ErrFreed) {
+		t.Fatalf("Expected ErrFreed adding to a freed model, got %v", err)
+	}
+
+	if _, err = model.Size(); !errors.Is(err, ErrFreed) {
+		t.Fatalf("Expected ErrFreed sizing a freed model, got %v", err)
+	}
+
+	if _, err = model.ExecuteQuery(&query); !errors.As(err, &queryError) || !errors.Is(err, ErrNilArgument) {
+		t.Fatalf("Expected a *QueryError executing against a freed model, got %v", err)
+	}
+
+	// errors are wrapped so that they can still be inspected through the errors returned by Marshal
+	var subject *Node
+	if subject, err = NewNodeFromUriString(world, "http://example.org/subject"); err != nil {
+		t.Fatalf("Failed to create subject node: %s", err.Error())
+	}
+	defer subject.Free()
+
+	if err = Marshal(model, subject, &testAddress{Street: "1 Main Street"}); !errors.As(err, &modelError) || !errors.Is(err, ErrFreed) {
+		t.Fatalf("Expected a *ModelError wrapping ErrFreed marshalling into a freed model, got %v", err)
+	}
+
+	var nilModel *Model
+	nilModel.Free()
+	if _, err = nilModel.Count(nil); !errors.Is(err, ErrFreed) {
+		t.Fatalf("Expected ErrFreed counting a nil model, got %v", err)
+	}
+
+	// a freed statement can be cleared without panicking but cannot be cloned
+	statement.Free()
+	statement.Clear()
+
+	if _, err = statement.DeepClone(); !errors.As(err, &statementError) || !errors.Is(err, ErrFreed) {
+		t.Fatalf("Expected a *StatementError cloning a freed statement, got %v", err)
+	}
+}
+
+//Test_EnumerateCapabilities tests the following sequence:
+//	- Listing parsers before the world is opened
+//	- Listing the parsers, serializers, query languages, query result formats and storages
+//	- Constructing each listed parser by name
+func Test_EnumerateCapabilities(t *testing.T) {
+	var err error
+
+	world := NewWorld()
+
+	if _, err = world.Parsers(); !errors.Is(err, ErrWorldClosed) {
+		t.Fatalf("Expected ErrWorldClosed listing parsers before the world is opened, got %v", err)
+	}
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// findSyntax returns the description with the given name, or nil if there is none
+	findSyntax := func(descriptions []SyntaxDescription, name string) *SyntaxDescription {
+		for i := range descriptions {
+			if descriptions[i].Name == name {
+				return &descriptions[i]
+			}
+		}
+		return nil
+	}
+
+	parsers, err := world.Parsers()
+	if err != nil {
+		t.Fatalf("Failed to list parsers: %s", err.Error())
+	}
+
+	for _, description := range parsers {
+		fmt.Printf("Parser: %s (%s) %v %v\n", description.Name, description.Label, description.MimeTypes, description.Uris)
+
+		// each parser listed may be constructed by name
+		parser, err := NewParser(world, description.Name, "")
+		if err != nil {
+			t.Fatalf("Failed to create listed parser %s: %s", description.Name, err.Error())
+		}
+		parser.Free()
+	}
+
+	turtle := findSyntax(parsers, "turtle")
+	if turtle == nil {
+		t.Fatalf("Expected the turtle parser to be listed")
+	}
+
+	hasTurtleMimeType := false
+	for _, mimeType := range turtle.MimeTypes {
+		hasTurtleMimeType = hasTurtleMimeType || mimeType == "text/turtle"
+	}
+	if !hasTurtleMimeType {
+		t.Fatalf("Expected the turtle parser to list the text/turtle mime type, got %v", turtle.MimeTypes)
+	}
+
+	serializers, err := world.Serializers()
+	if err != nil {
+		t.Fatalf("Failed to list serializers: %s", err.Error())
+	}
+
+	for _, description := range serializers {
+		fmt.Printf("Serializer: %s (%s) %v\n", description.Name, description.Label, description.MimeTypes)
+	}
+
+	if findSyntax(serializers, "ntriples") == nil {
+		t.Fatalf("Expected the ntriples serializer to be listed")
+	}
+
+	queryLanguages, err := world.QueryLanguages()
+	if err != nil {
+		t.Fatalf("Failed to list query languages: %s", err.Error())
+	}
+
+	for _, description := range queryLanguages {
+		fmt.Printf("Query language: %s (%s) %v\n", description.Name, description.Label, description.Uris)
+	}
+
+	if findSyntax(queryLanguages, "sparql") == nil {
+		t.Fatalf("Expected the sparql query language to be listed")
+	}
+
+	queryResultFormats, err := world.QueryResultFormats()
+	if err != nil {
+		t.Fatalf("Failed to list query result formats: %s", err.Error())
+	}
+
+	for _, description := range queryResultFormats {
+		fmt.Printf("Query result format: %s (%s) %v\n", description.Name, description.Label, description.MimeTypes)
+	}
+
+	if len(queryResultFormats) == 0 {
+		t.Fatalf("Expected query result formats to be listed")
+	}
+
+	storages, err := world.Storages()
+	if err != nil {
+		t.Fatalf("Failed to list storages: %s", err.Error())
+	}
+
+	hasMemory := false
+	for _, description := range storages {
+		fmt.Printf("Storage: %s (%s)\n", description.Name, description.Label)
+		hasMemory = hasMemory || description.Name == "memory"
+	}
+
+	if !hasMemory {
+		t.Fatalf("Expected the memory storage to be listed")
+	}
+}
+
+//Test_GuessParser tests the following sequence:
+//	- Guessing a parser from a file extension, a mime type and content
+//	- Constructing a parser that is guessed from data read from an io.Reader
+//	- Constructing a parser that is guessed from content
+func Test_GuessParser(t *testing.T) {
+	storageType := "memory"
+
+	var err error
+	var storage *Storage
+	var model *Model
+	var uploadUri *Uri
+	var fileUri *Uri
+	var parserName string
+
+	world := NewWorld()
+
+	if err = world.Open(); err != nil {
+		t.Fatalf("World failed to open: %s", err.Error())
+	}
+	defer world.Close()
+
+	// construct a storage provider
+	if storage, err = NewStorage(world, storageType, "test", ""); err != nil {
+		t.Fatalf("Failed to create storage: %s", err.Error())
+	}
+	defer storage.Free()
+
+	// construct a model
+	if model, err = NewModel(world, storage, ""); err != nil {
+		t.Fatalf("Failed to construct model: %s", err.Error())
+	}
+	defer model.Free()
+
+	// a URI without an extension gives no hint of the syntax
+	if uploadUri, err = NewUri(world, "http://example.org/upload"); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer uploadUri.Free()
+
+	if fileUri, err = NewUri(world, "http://example.org/data.ttl"); err != nil {
+		t.Fatalf("Failed to create URI: %s", err.Error())
+	}
+	defer fileUri.Free()
+
+	if parserName = world.GuessParserName(fileUri); parserName != "turtle" {
+		t.Fatalf("Expected turtle to be guessed from the file extension, got %q", parserName)
+	}
+
+	if parserName, err = world.GuessParser("text/turtle", nil, nil); err != nil || parserName != "turtle" {
+		t.Fatalf("Expected turtle to be guessed from the mime type, got %q (%v)", parserName, err)
+	}
+
+	if parserName, err = world.GuessParser("", []byte(rdfxml_content), uploadUri); err != nil || parserName != "rdfxml" {
+		t.Fatalf("Expected rdfxml to be guessed from the content, got %q (%v)", parserName, err)
+	}
+	fmt.Printf("Guessed parser from content: %s\n", parserName)
+
+	turtleContent := `@prefix dc: <http://purl.org/dc/elements/1.1/> .
+<http://example.org/a> dc:title "A" .
+<http://example.org/b> dc:title "B" .
+`
+
+	parser, data, err := NewParserForReader(world, "", strings.NewReader(turtleContent), uploadUri)
+	if err != nil {
+		t.Fatalf("Failed to create parser for reader: %s", err.Error())
+	}
+	defer parser.Free()
+
+	if parser.Name != "turtle" {
+		t.Fatalf("Expected a turtle parser to be chosen from the content, got %q", parser.Name)
+	}
+
+	// the data read to guess the syntax is still parsed
+	if err = parser.ParseReaderIntoModel(data, uploadUri, model); err != nil {
+		t.Fatalf("Failed to parse data from reader: %s", err.Error())
+	}
+
+	if size, err := model.Size(); err != nil || size != 2 {
+		t.Fatalf("Expected 2 statements to be parsed, got %d (%v)", size, err)
+	}
+
+	xmlParser, err := NewParserForContent(world, "", []byte(rdfxml_content), nil)
+	if err != nil {
+		t.Fatalf("Failed to create parser for content: %s", err.Error())
+	}
+	defer xmlParser.Free()
+
+	if err = xmlParser.ParseStringIntoModel(rdfxml_content, uploadUri, model); err != nil {
+		t.Fatalf("Failed to parse content with the guessed parser: %s", err.Error())
+	}
+}
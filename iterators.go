@@ -0,0 +1,259 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information
+* on the Redland libraries that this package wraps
+*
+* This golibrdf package is:
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+*
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+ */
+
+package golibrdf
+
+// #cgo linux pkg-config: redland raptor2
+// #cgo LDFLAGS: -lrdf
+// #include <stdlib.h>
+// #include <string.h>
+// #include <strings.h>
+// #include <librdf.h>
+import "C"
+
+import (
+	"context"
+	"iter"
+	"runtime"
+	"unsafe"
+)
+
+//Statements returns an iterator over the statements in the model that match the given partial statement
+//	If partialStatement is nil all statements in the model are returned.  Statements are read from the
+//	librdf stream on the caller's goroutine and the stream is freed when the loop ends or breaks.
+//	The receiver owns each statement returned.
+func (model *Model) Statements(partialStatement *Statement) iter.Seq2[*Statement, error] {
+	if err := model.validate("find statements"); err != nil {
+		return errorSeq[*Statement](err)
+	}
+
+	return statementStreamSeq(model.world, func() *C.librdf_stream {
+		if partialStatement == nil {
+			return C.librdf_model_as_stream(model.librdf_model)
+		}
+		return C.librdf_model_find_statements(model.librdf_model, partialStatement.librdf_statement)
+	})
+}
+
+//StatementsInContext returns an iterator over the statements in the given context that match the given partial statement
+//	If partialStatement is nil all statements in the context are returned
+func (model *Model) StatementsInContext(partialStatement *Statement, contextNode *Node) iter.Seq2[*Statement, error] {
+	if err := model.validate("find statements in context", checkNodes(contextNode)); err != nil {
+		return errorSeq[*Statement](err)
+	}
+
+	return statementStreamSeq(model.world, func() *C.librdf_stream {
+		if partialStatement == nil {
+			return C.librdf_model_context_as_stream(model.librdf_model, contextNode.librdf_node)
+		}
+		return C.librdf_model_find_statements_in_context(model.librdf_model, partialStatement.librdf_statement, contextNode.librdf_node)
+	})
+}
+
+//Targets returns an iterator over the targets matching a subject + predicate pair
+//	Nodes are read from the librdf iterator on the caller's goroutine and the iterator is freed when the loop ends or breaks.
+//	The receiver owns each node returned and must call Free on it, as nodes are not freed when garbage collected.
+func (model *Model) Targets(subject *Node, predicate *Node) iter.Seq2[*Node, error] {
+	if err := model.validate("find targets", checkNodes(subject, predicate)); err != nil {
+		return errorSeq[*Node](err)
+	}
+
+	return nodeIteratorSeq(model.world, func() *C.librdf_iterator {
+		return C.librdf_model_get_targets(model.librdf_model, subject.librdf_node, predicate.librdf_node)
+	})
+}
+
+//Sources returns an iterator over the sources matching a predicate + object pair
+//	The receiver owns each node returned and must call Free on it, as nodes are not freed when garbage collected.
+func (model *Model) Sources(predicate *Node, object *Node) iter.Seq2[*Node, error] {
+	if err := model.validate("find sources", checkNodes(predicate, object)); err != nil {
+		return errorSeq[*Node](err)
+	}
+
+	return nodeIteratorSeq(model.world, func() *C.librdf_iterator {
+		return C.librdf_model_get_sources(model.librdf_model, predicate.librdf_node, object.librdf_node)
+	})
+}
+
+//Arcs returns an iterator over the arcs (predicates) matching a subject + object pair
+//	The receiver owns each node returned and must call Free on it, as nodes are not freed when garbage collected.
+func (model *Model) Arcs(subject *Node, object *Node) iter.Seq2[*Node, error] {
+	if err := model.validate("find arcs", checkNodes(subject, object)); err != nil {
+		return errorSeq[*Node](err)
+	}
+
+	return nodeIteratorSeq(model.world, func() *C.librdf_iterator {
+		return C.librdf_model_get_arcs(model.librdf_model, subject.librdf_node, object.librdf_node)
+	})
+}
+
+//IncomingArcs returns an iterator over the arcs (predicates) of statements that have the given node as their object
+//	The receiver owns each node returned and must call Free on it, as nodes are not freed when garbage collected.
+func (model *Model) IncomingArcs(node *Node) iter.Seq2[*Node, error] {
+	if err := model.validate("find incoming arcs", checkNodes(node)); err != nil {
+		return errorSeq[*Node](err)
+	}
+
+	return nodeIteratorSeq(model.world, func() *C.librdf_iterator {
+		return C.librdf_model_get_arcs_in(model.librdf_model, node.librdf_node)
+	})
+}
+
+//OutgoingArcs returns an iterator over the arcs (predicates) of statements that have the given node as their subject
+//	The receiver owns each node returned and must call Free on it, as nodes are not freed when garbage collected.
+func (model *Model) OutgoingArcs(node *Node) iter.Seq2[*Node, error] {
+	if err := model.validate("find outgoing arcs", checkNodes(node)); err != nil {
+		return errorSeq[*Node](err)
+	}
+
+	return nodeIteratorSeq(model.world, func() *C.librdf_iterator {
+		return C.librdf_model_get_arcs_out(model.librdf_model, node.librdf_node)
+	})
+}
+
+//Contexts returns an iterator over the context nodes in the model
+//	The receiver owns each node returned and must call Free on it, as nodes are not freed when garbage collected.
+func (model *Model) Contexts() iter.Seq2[*Node, error] {
+	if err := model.validate("find contexts"); err != nil {
+		return errorSeq[*Node](err)
+	}
+
+	return nodeIteratorSeq(model.world, func() *C.librdf_iterator {
+		return C.librdf_model_get_contexts(model.librdf_model)
+	})
+}
+
+//statementStreamSeq returns an iterator over copies of the statements from the librdf stream returned by newStream
+//	newStream is called each time iteration starts and the stream is freed when iteration stops
+func statementStreamSeq(world *World, newStream func() *C.librdf_stream) iter.Seq2[*Statement, error] {
+	return func(yield func(*Statement, error) bool) {
+		stream := newStream()
+
+		if stream == nil {
+			yield(nil, ErrNullResult)
+			return
+		}
+		defer C.librdf_free_stream(stream)
+
+		for C.librdf_stream_end(stream) == 0 {
+			librdfStatement := C.librdf_stream_get_object(stream)
+
+			if librdfStatement == nil {
+				yield(nil, ErrNullResult)
+				return
+			}
+
+			// the stream owns the statement so a copy is made for the receiver
+			statement := Statement{world: world}
+			statement.librdf_statement = C.librdf_new_statement_from_statement(librdfStatement)
+			runtime.SetFinalizer(&statement, (*Statement).Free)
+
+			if !yield(&statement, nil) {
+				return
+			}
+
+			C.librdf_stream_next(stream)
+		}
+	}
+}
+
+//nodeIteratorSeq returns an iterator over copies of the nodes from the librdf iterator returned by newIterator
+//	newIterator is called each time iteration starts and the librdf iterator is freed when iteration stops.
+//	Unlike statements, the copies have no finalizer because a node passed to NewStatementFromNodes is freed by the statement
+func nodeIteratorSeq(world *World, newIterator func() *C.librdf_iterator) iter.Seq2[*Node, error] {
+	return func(yield func(*Node, error) bool) {
+		iterator := newIterator()
+
+		if iterator == nil {
+			yield(nil, ErrNullResult)
+			return
+		}
+		defer C.librdf_free_iterator(iterator)
+
+		for C.librdf_iterator_end(iterator) == 0 {
+			librdfNode := (*C.librdf_node)(unsafe.Pointer(C.librdf_iterator_get_object(iterator)))
+
+			if librdfNode == nil {
+				yield(nil, ErrNullResult)
+				return
+			}
+
+			node, err := NewNode(world)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			// the iterator owns the node so a copy is made for the receiver
+			node.librdf_node = C.librdf_new_node_from_node(librdfNode)
+
+			if !yield(node, nil) {
+				return
+			}
+
+			C.librdf_iterator_next(iterator)
+		}
+	}
+}
+
+//errorSeq returns an iterator that yields err and nothing else
+func errorSeq[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
+
+//seqToChannel runs an iterator on a new goroutine, sending each value to the returned channel
+//	Iteration stops when ctx is cancelled, in which case free is called for any value that was not sent.
+//	The returned error channel receives at most one error (including ctx.Err() on cancellation) and
+//	is closed once iteration has finished
+func seqToChannel[T any](ctx context.Context, seq iter.Seq2[T, error], bufferSize int, free func(T)) (chan T, chan error) {
+	chanValue := make(chan T, bufferSize)
+	chanErr := make(chan error, 1)
+
+	go func() {
+		defer close(chanErr)
+		defer close(chanValue)
+
+		for value, err := range seq {
+			if err != nil {
+				chanErr <- err
+				return
+			}
+
+			if err = ctx.Err(); err != nil {
+				free(value)
+				chanErr <- err
+				return
+			}
+
+			select {
+			case chanValue <- value:
+			case <-ctx.Done():
+				free(value)
+				chanErr <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return chanValue, chanErr
+}
@@ -0,0 +1,373 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+// #cgo linux pkg-config: redland raptor2
+// #cgo LDFLAGS: -lrdf
+// #include <stdlib.h>
+// #include <string.h>
+// #include <strings.h>
+// #include <stdint.h>
+// #include <librdf.h>
+//
+// extern int golibrdfLogMessage(uintptr_t handle, uintptr_t collector, librdf_log_message *message);
+//
+// /* the collector gathering messages for the operation running on this thread, if any */
+// static __thread uintptr_t golibrdf_log_collector;
+//
+// static int golibrdf_log_handler(void *user_data, librdf_log_message *message) {
+//   return golibrdfLogMessage((uintptr_t)user_data, golibrdf_log_collector, message);
+// }
+//
+// static uintptr_t golibrdf_swap_log_collector(uintptr_t collector) {
+//   uintptr_t previous = golibrdf_log_collector;
+//   golibrdf_log_collector = collector;
+//   return previous;
+// }
+//
+// static void golibrdf_set_logger(librdf_world *world, uintptr_t handle) {
+//   librdf_world_set_logger(world, (void*)handle, golibrdf_log_handler);
+// }
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+//LogLevel is the severity of a message logged by librdf
+type LogLevel int
+
+//levels of messages logged by librdf
+const (
+	LogLevelNone  LogLevel = C.LIBRDF_LOG_NONE
+	LogLevelDebug LogLevel = C.LIBRDF_LOG_DEBUG
+	LogLevelInfo  LogLevel = C.LIBRDF_LOG_INFO
+	LogLevelWarn  LogLevel = C.LIBRDF_LOG_WARN
+	LogLevelError LogLevel = C.LIBRDF_LOG_ERROR
+	LogLevelFatal LogLevel = C.LIBRDF_LOG_FATAL
+)
+
+//String returns the name of the log level
+func (level LogLevel) String() string {
+	switch level {
+	case LogLevelNone:
+		return "none"
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warning"
+	case LogLevelError:
+		return "error"
+	case LogLevelFatal:
+		return "fatal"
+	}
+	return fmt.Sprintf("level %d", int(level))
+}
+
+//LogFacility is the part of librdf that logged a message
+type LogFacility int
+
+//parts of librdf that log messages
+const (
+	LogFacilityNone       LogFacility = C.LIBRDF_FROM_NONE
+	LogFacilityConcepts   LogFacility = C.LIBRDF_FROM_CONCEPTS
+	LogFacilityDigest     LogFacility = C.LIBRDF_FROM_DIGEST
+	LogFacilityFiles      LogFacility = C.LIBRDF_FROM_FILES
+	LogFacilityHash       LogFacility = C.LIBRDF_FROM_HASH
+	LogFacilityInit       LogFacility = C.LIBRDF_FROM_INIT
+	LogFacilityIterator   LogFacility = C.LIBRDF_FROM_ITERATOR
+	LogFacilityList       LogFacility = C.LIBRDF_FROM_LIST
+	LogFacilityModel      LogFacility = C.LIBRDF_FROM_MODEL
+	LogFacilityNode       LogFacility = C.LIBRDF_FROM_NODE
+	LogFacilityParser     LogFacility = C.LIBRDF_FROM_PARSER
+	LogFacilityQuery      LogFacility = C.LIBRDF_FROM_QUERY
+	LogFacilitySerializer LogFacility = C.LIBRDF_FROM_SERIALIZER
+	LogFacilityStatement  LogFacility = C.LIBRDF_FROM_STATEMENT
+	LogFacilityStorage    LogFacility = C.LIBRDF_FROM_STORAGE
+	LogFacilityStream     LogFacility = C.LIBRDF_FROM_STREAM
+	LogFacilityUri        LogFacility = C.LIBRDF_FROM_URI
+	LogFacilityUtf8       LogFacility = C.LIBRDF_FROM_UTF8
+	LogFacilityMemory     LogFacility = C.LIBRDF_FROM_MEMORY
+	LogFacilityRaptor     LogFacility = C.LIBRDF_FROM_RAPTOR
+)
+
+//names of the log facilities
+var logFacilityNames = map[LogFacility]string{
+	LogFacilityNone:       "none",
+	LogFacilityConcepts:   "concepts",
+	LogFacilityDigest:     "digest",
+	LogFacilityFiles:      "files",
+	LogFacilityHash:       "hash",
+	LogFacilityInit:       "init",
+	LogFacilityIterator:   "iterator",
+	LogFacilityList:       "list",
+	LogFacilityModel:      "model",
+	LogFacilityNode:       "node",
+	LogFacilityParser:     "parser",
+	LogFacilityQuery:      "query",
+	LogFacilitySerializer: "serializer",
+	LogFacilityStatement:  "statement",
+	LogFacilityStorage:    "storage",
+	LogFacilityStream:     "stream",
+	LogFacilityUri:        "uri",
+	LogFacilityUtf8:       "utf8",
+	LogFacilityMemory:     "memory",
+	LogFacilityRaptor:     "raptor",
+}
+
+//String returns the name of the log facility
+func (facility LogFacility) String() string {
+	if name, ok := logFacilityNames[facility]; ok {
+		return name
+	}
+	return fmt.Sprintf("facility %d", int(facility))
+}
+
+//LogMessage is a message logged by librdf, raptor or rasqal
+//	Line, Column and Byte are set from the parser locator when known, and are otherwise less than 1
+type LogMessage struct {
+	Level    LogLevel
+	Facility LogFacility
+	Code     int
+	Message  string
+	Uri      string
+	File     string
+	Line     int
+	Column   int
+	Byte     int
+}
+
+//String formats the message with its location, if known
+func (message LogMessage) String() string {
+	location := message.Uri
+	if location == "" {
+		location = message.File
+	}
+
+	if message.Line > 0 {
+		if location != "" {
+			location += ":"
+		}
+		location += fmt.Sprintf("%d", message.Line)
+		if message.Column > 0 {
+			location += fmt.Sprintf(":%d", message.Column)
+		}
+	}
+
+	if location == "" {
+		return fmt.Sprintf("%s: %s", message.Level, message.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, message.Level, message.Message)
+}
+
+//slogLevelFatal is the slog level used for messages logged at LogLevelFatal
+const slogLevelFatal = slog.LevelError + 4
+
+//worldLog receives the messages logged by librdf for a World
+//	It is referred to by a cgo.Handle passed to librdf rather than the World itself, so that the World can still be garbage collected.
+//	Messages are passed to the collector of the operation that logged them and to the logger set by SetLogger.
+//	When there is neither, messages are written to stderr as librdf does.
+//	librdf may log from any goroutine that calls into it, so the logger is guarded by mutex.
+type worldLog struct {
+	mutex  sync.Mutex
+	logger *slog.Logger
+}
+
+//logCollector gathers the messages logged while an operation runs
+//	librdf logs on the thread of the call that caused the message, so a collector is installed in a C thread-local
+//	variable while its goroutine is locked to the thread.  Operations running at the same time on other goroutines
+//	therefore only see their own messages.
+type logCollector struct {
+	log      *worldLog
+	handle   cgo.Handle
+	previous C.uintptr_t
+	messages []LogMessage
+}
+
+//installLogger routes the messages logged by librdf for the world to its worldLog
+//	installLogger is called before the librdf world is opened so that messages logged while opening are included
+func (world *World) installLogger() {
+	if world.log == nil {
+		world.log = &worldLog{}
+	}
+	world.logHandle = cgo.NewHandle(world.log)
+
+	C.golibrdf_set_logger(world.librdf_world, C.uintptr_t(world.logHandle))
+}
+
+//removeLogger releases the handle used by librdf to reach the worldLog, once the librdf world has been freed
+//	The worldLog is kept so that a logger set by SetLogger remains in place if the world is opened again
+func (world *World) removeLogger() {
+	if world.logHandle != 0 {
+		world.logHandle.Delete()
+		world.logHandle = 0
+	}
+}
+
+//startLogCollector begins gathering the messages logged for the world by the calling goroutine
+//	The goroutine is locked to its thread until the corresponding stopLogCollector call, which must be made
+//	on the same goroutine.  Collectors may be nested, in which case the innermost collector gathers the messages.
+func (world *World) startLogCollector() *logCollector {
+	runtime.LockOSThread()
+
+	collector := &logCollector{log: world.log}
+	collector.handle = cgo.NewHandle(collector)
+	collector.previous = C.golibrdf_swap_log_collector(C.uintptr_t(collector.handle))
+
+	return collector
+}
+
+//stopLogCollector stops gathering messages for a collector, restoring any enclosing collector, and returns the messages gathered
+func (world *World) stopLogCollector(collector *logCollector) []LogMessage {
+	C.golibrdf_swap_log_collector(collector.previous)
+	collector.handle.Delete()
+
+	runtime.UnlockOSThread()
+
+	return collector.messages
+}
+
+//SetLogger routes the messages logged by librdf, raptor and rasqal for the world to logger
+//	The level, facility, code and any parser location of each message are recorded as attributes.
+//	Messages are still gathered for errors such as ParseError.  A nil logger restores writing to stderr.
+//	SetLogger may be called before or after the world is opened.
+func (world *World) SetLogger(logger *slog.Logger) {
+	if world.log == nil {
+		world.log = &worldLog{}
+	}
+
+	world.log.mutex.Lock()
+	world.log.logger = logger
+	world.log.mutex.Unlock()
+}
+
+//handle passes a logged message to the collector of the operation that logged it, if any, and the logger
+//	A collector started for another World does not receive the message
+func (log *worldLog) handle(message LogMessage, collector *logCollector) {
+	// the collector is only used by the goroutine that logged the message, so it needs no locking
+	isCollected := collector != nil && collector.log == log
+	if isCollected {
+		collector.messages = append(collector.messages, message)
+	}
+
+	// the logger is called without holding the mutex so that its handler is free to use the world
+	log.mutex.Lock()
+	logger := log.logger
+	log.mutex.Unlock()
+
+	switch {
+	case logger != nil:
+		logger.LogAttrs(context.Background(), message.slogLevel(), message.Message, message.slogAttrs()...)
+	case !isCollected:
+		fmt.Fprintf(os.Stderr, "%s\n", message)
+	}
+}
+
+//slogLevel returns the slog level corresponding to the message level
+func (message LogMessage) slogLevel() slog.Level {
+	switch message.Level {
+	case LogLevelDebug, LogLevelNone:
+		return slog.LevelDebug
+	case LogLevelInfo:
+		return slog.LevelInfo
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	}
+
+	return slogLevelFatal
+}
+
+//slogAttrs returns the facility, code and known location of the message as slog attributes
+func (message LogMessage) slogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("facility", message.Facility.String()),
+		slog.Int("code", message.Code),
+	}
+
+	var locator []interface{}
+	if message.Uri != "" {
+		locator = append(locator, slog.String("uri", message.Uri))
+	}
+	if message.File != "" {
+		locator = append(locator, slog.String("file", message.File))
+	}
+	if message.Line > 0 {
+		locator = append(locator, slog.Int("line", message.Line))
+	}
+	if message.Column > 0 {
+		locator = append(locator, slog.Int("column", message.Column))
+	}
+	if message.Byte > 0 {
+		locator = append(locator, slog.Int("byte", message.Byte))
+	}
+
+	if len(locator) > 0 {
+		attrs = append(attrs, slog.Group("locator", locator...))
+	}
+
+	return attrs
+}
+
+//logMessagesError returns err followed by the first error logged, if any
+//	The returned error wraps err so that it can still be tested for with errors.Is
+func logMessagesError(err error, messages []LogMessage) error {
+	for _, message := range messages {
+		if message.Level >= LogLevelError {
+			return fmt.Errorf("%w  %s", err, message)
+		}
+	}
+
+	return err
+}
+
+//newLogMessage copies the details of a message logged by librdf
+func newLogMessage(message *C.librdf_log_message) LogMessage {
+	logMessage := LogMessage{
+		Level:    LogLevel(C.librdf_log_message_level(message)),
+		Facility: LogFacility(C.librdf_log_message_facility(message)),
+		Code:     int(C.librdf_log_message_code(message)),
+		Message:  C.GoString(C.librdf_log_message_message(message)),
+	}
+
+	if locator := C.librdf_log_message_locator(message); locator != nil {
+		logMessage.Line = int(locator.line)
+		logMessage.Column = int(locator.column)
+		logMessage.Byte = int(locator.byte)
+		logMessage.File = C.GoString(locator.file)
+		if locator.uri != nil {
+			logMessage.Uri = C.GoString((*C.char)(unsafe.Pointer(C.raptor_uri_as_string(locator.uri))))
+		}
+	}
+
+	return logMessage
+}
@@ -0,0 +1,605 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	urlType           = reflect.TypeOf(url.URL{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	byteSliceType     = reflect.TypeOf([]byte(nil))
+)
+
+//maximum number of cells read from an rdf:List, guarding against cyclic lists
+const maxListLength = 1 << 20
+
+//maximum depth of nested structs that are marshalled or unmarshalled, guarding against cycles that are not detected by structPath
+const maxStructDepth = 1 << 10
+
+//structPath records the structs being marshalled or unmarshalled by the enclosing calls so that a cycle,
+//	such as a pointer back to an enclosing struct or a blank node that refers to itself, is reported rather than
+//	recursing until the stack overflows
+type structPath struct {
+	keys []structPathKey
+}
+
+//structPathKey identifies a struct on a structPath by its type along with its address when marshalling
+//	or the node it is read from when unmarshalling
+type structPathKey struct {
+	structType reflect.Type
+	address    uintptr
+	node       NodeKey
+}
+
+//rdfFieldTag holds the options given in the rdf tag of a struct field being marshalled
+type rdfFieldTag struct {
+	predicate string
+	language  string
+	datatype  string
+	list      bool
+	iri       bool
+	omitempty bool
+}
+
+//Marshal adds statements describing the struct v to the model, using subject as the subject of the statements
+//	Fields are mapped to statements using tags of the form `rdf:"<predicate-iri>,options"` where options may be:
+//	lang=tag to write strings as literals with a language tag, datatype=<iri> to write literals with the given datatype,
+//	iri to write strings or *url.URL values as resources, list to write a slice as an rdf:List rather than
+//	as repeated statements, and omitempty to skip zero values.  Untagged fields and fields tagged `rdf:"-"` are ignored.
+//
+//	Literals are built as for NewNodeFromValue, with named types and encoding.TextMarshaler values also supported.
+//	*Node fields are written as given.  Nested structs are written as blank nodes described by their own fields,
+//	and an error is returned if a struct refers back to an enclosing struct.  nil pointers, slices and *Node values are skipped.
+func Marshal(model *Model, subject *Node, v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return errors.New("Unable to marshal value.  A struct or pointer to a struct is required.")
+	}
+
+	return model.marshalStruct(subject, value, &structPath{})
+}
+
+//Unmarshal fills the fields of the struct pointed to by v from the statements in the model about subject
+//	Fields are mapped as for Marshal.  Fields with no matching statement are set to their zero value.  Slices
+//	tagged list are read from an rdf:List, other slices from every matching statement, ordered as for Node.Compare.
+//	Fields tagged lang=tag are read only from literals with that language tag.  Literals are converted as for
+//	QueryResultItem.Decode.  Nested structs are read from the statements about the node that is the object of
+//	their predicate, and an error is returned if that node is already being read into an enclosing struct of the same type.
+func Unmarshal(model *Model, subject *Node, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("Unable to unmarshal value.  A non-nil pointer to a struct is required.")
+	}
+
+	return model.unmarshalStruct(subject, value.Elem(), &structPath{})
+}
+
+//parseRdfFieldTag parses the rdf tag of a struct field being marshalled
+//	IRIs may be enclosed in angle brackets, which allows them to contain commas
+func parseRdfFieldTag(tag string) (rdfFieldTag, error) {
+	var fieldTag rdfFieldTag
+
+	parts := splitRdfFieldTag(tag)
+	fieldTag.predicate = strings.TrimSuffix(strings.TrimPrefix(parts[0], "<"), ">")
+	if fieldTag.predicate == "" {
+		return fieldTag, errors.New("missing predicate IRI")
+	}
+
+	for _, option := range parts[1:] {
+		switch {
+		case option == "list":
+			fieldTag.list = true
+		case option == "iri":
+			fieldTag.iri = true
+		case option == "omitempty":
+			fieldTag.omitempty = true
+		case strings.HasPrefix(option, "lang="):
+			fieldTag.language = strings.TrimPrefix(option, "lang=")
+		case strings.HasPrefix(option, "datatype="):
+			fieldTag.datatype = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(option, "datatype="), "<"), ">")
+		default:
+			return fieldTag, fmt.Errorf("unknown option %q", option)
+		}
+	}
+
+	return fieldTag, nil
+}
+
+//splitRdfFieldTag splits an rdf tag at commas that are not within angle brackets
+func splitRdfFieldTag(tag string) []string {
+	var parts []string
+
+	start := 0
+	inIri := false
+	for i, c := range tag {
+		switch {
+		case c == '<':
+			inIri = true
+		case c == '>':
+			inIri = false
+		case c == ',' && !inIri:
+			parts = append(parts, strings.TrimSpace(tag[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(parts, strings.TrimSpace(tag[start:]))
+}
+
+//isNestedStruct returns true if values of type t are marshalled as blank nodes rather than literals
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && t != urlType && t != nodePointerType.Elem() &&
+		!t.Implements(textMarshalerType) && !reflect.PointerTo(t).Implements(textMarshalerType) &&
+		!reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+//isRepeated returns true if values of type t are marshalled as more than one object
+func isRepeated(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t != byteSliceType
+}
+
+//taggedFields calls fn with each struct field that has an rdf tag, along with its parsed tag and predicate node
+func (model *Model) taggedFields(value reflect.Value, fn func(field reflect.Value, fieldTag rdfFieldTag, predicate *Node) error) error {
+	structType := value.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		tag := field.Tag.Get("rdf")
+		if tag == "" || tag == "-" || field.PkgPath != "" {
+			continue
+		}
+
+		fieldTag, err := parseRdfFieldTag(tag)
+		if err != nil {
+			return fmt.Errorf("Invalid rdf tag on field %s: %w", field.Name, err)
+		}
+
+		predicate, err := NewNodeFromUriString(model.world, fieldTag.predicate)
+		if err != nil {
+			return err
+		}
+
+		err = fn(value.Field(i), fieldTag, predicate)
+		predicate.Free()
+
+		if err != nil {
+			return fmt.Errorf("Unable to map field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+//enter adds a struct to the path, returning an error if it is already on the path or the path is too deep
+func (path *structPath) enter(key structPathKey) error {
+	if len(path.keys) == maxStructDepth {
+		return errors.New("structs are nested too deeply")
+	}
+
+	if key.address != 0 || key.node != "" {
+		for _, enclosing := range path.keys {
+			if enclosing == key {
+				return fmt.Errorf("cycle detected at a struct of type %s", key.structType)
+			}
+		}
+	}
+
+	path.keys = append(path.keys, key)
+	return nil
+}
+
+//leave removes the struct most recently added by enter
+func (path *structPath) leave() {
+	path.keys = path.keys[:len(path.keys)-1]
+}
+
+//marshalStruct adds statements describing each tagged field of a struct
+func (model *Model) marshalStruct(subject *Node, value reflect.Value, path *structPath) error {
+	key := structPathKey{structType: value.Type()}
+	if value.CanAddr() {
+		key.address = value.UnsafeAddr()
+	}
+
+	if err := path.enter(key); err != nil {
+		return err
+	}
+	defer path.leave()
+
+	return model.taggedFields(value, func(field reflect.Value, fieldTag rdfFieldTag, predicate *Node) error {
+		if fieldTag.omitempty && field.IsZero() {
+			return nil
+		}
+
+		if !isRepeated(field.Type()) {
+			return model.marshalStatement(subject, predicate, field, fieldTag, path)
+		}
+
+		if !fieldTag.list {
+			for i := 0; i < field.Len(); i++ {
+				if err := model.marshalStatement(subject, predicate, field.Index(i), fieldTag, path); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		head, err := model.marshalList(field, fieldTag, path)
+		if err != nil {
+			return err
+		}
+		defer head.Free()
+
+		return model.addTriple(subject, predicate, head)
+	})
+}
+
+//marshalStatement adds a statement with a Go value as its object
+func (model *Model) marshalStatement(subject *Node, predicate *Node, value reflect.Value, fieldTag rdfFieldTag, path *structPath) error {
+	object, err := model.marshalObject(value, fieldTag, path)
+	if err != nil || object == nil {
+		return err
+	}
+	defer object.Free()
+
+	return model.addTriple(subject, predicate, object)
+}
+
+//marshalList adds the statements forming an rdf:List of the elements of a slice and returns the head of the list
+func (model *Model) marshalList(value reflect.Value, fieldTag rdfFieldTag, path *structPath) (*Node, error) {
+	first, err := NewNodeFromUriString(model.world, RdfFirst)
+	if err != nil {
+		return nil, err
+	}
+	defer first.Free()
+
+	rest, err := NewNodeFromUriString(model.world, RdfRest)
+	if err != nil {
+		return nil, err
+	}
+	defer rest.Free()
+
+	// the list is built from its tail so that each cell can refer to the next
+	next, err := NewNodeFromUriString(model.world, RdfNil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := value.Len() - 1; i >= 0; i-- {
+		cell, err := NewBlankNode(model.world)
+		if err == nil {
+			err = model.marshalStatement(cell, first, value.Index(i), fieldTag, path)
+		}
+		if err == nil {
+			err = model.addTriple(cell, rest, next)
+		}
+
+		next.Free()
+		if err != nil {
+			if cell != nil {
+				cell.Free()
+			}
+			return nil, err
+		}
+		next = cell
+	}
+
+	return next, nil
+}
+
+//marshalObject builds the node used as the object of a statement for a Go value
+//	nil is returned for nil pointers, which are skipped
+func (model *Model) marshalObject(value reflect.Value, fieldTag rdfFieldTag, path *structPath) (*Node, error) {
+	valueType := value.Type()
+
+	switch {
+	case valueType == nodePointerType:
+		return value.Interface().(*Node).clone(), nil
+	case value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface:
+		if value.IsNil() {
+			return nil, nil
+		}
+		if valueType == bigIntPointerType || valueType == bigRatPointerType || valueType == urlPointerType && !fieldTag.iri {
+			break
+		}
+		return model.marshalObject(value.Elem(), fieldTag, path)
+	case isNestedStruct(valueType):
+		blank, err := NewBlankNode(model.world)
+		if err != nil {
+			return nil, err
+		}
+		if err = model.marshalStruct(blank, value, path); err != nil {
+			blank.Free()
+			return nil, err
+		}
+		return blank, nil
+	}
+
+	if fieldTag.iri {
+		switch {
+		case value.Kind() == reflect.String:
+			return NewNodeFromUriString(model.world, value.String())
+		case valueType == urlType:
+			uri := value.Interface().(url.URL)
+			return NewNodeFromUriString(model.world, uri.String())
+		}
+		return nil, fmt.Errorf("unable to write a value of type %s as an IRI", valueType)
+	}
+
+	lexicalForm, datatype, err := literalForValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case fieldTag.language != "":
+		return NewNodeFromLiteralWithLanguage(model.world, lexicalForm, fieldTag.language)
+	case fieldTag.datatype != "":
+		return NewNodeFromTypedLiteralUriString(model.world, lexicalForm, fieldTag.datatype)
+	case datatype == "":
+		return NewNodeFromLiteral(model.world, lexicalForm)
+	}
+
+	return NewNodeFromTypedLiteralUriString(model.world, lexicalForm, datatype)
+}
+
+//literalForValue converts a Go value to a lexical form and datatype URI, including values of named types
+func literalForValue(value reflect.Value) (string, string, error) {
+	lexicalForm, datatype, err := formatLiteralValue(value.Interface())
+	if err == nil {
+		return lexicalForm, datatype, nil
+	}
+
+	if value.Type().Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), "", err
+	}
+
+	// named types such as `type Status string` are converted using their underlying kind
+	switch value.Kind() {
+	case reflect.String:
+		return formatLiteralValue(value.String())
+	case reflect.Bool:
+		return formatLiteralValue(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return formatLiteralValue(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return formatLiteralValue(value.Uint())
+	case reflect.Float32:
+		return formatLiteralValue(float32(value.Float()))
+	case reflect.Float64:
+		return formatLiteralValue(value.Float())
+	}
+
+	return "", "", err
+}
+
+//addTriple adds a statement built from copies of the given nodes
+func (model *Model) addTriple(subject *Node, predicate *Node, object *Node) error {
+	statement, err := NewStatementFromNodes(model.world, subject.clone(), predicate.clone(), object.clone())
+	if err != nil {
+		return err
+	}
+	defer statement.Free()
+
+	return model.addStatementWithOptionalContext(nil, statement)
+}
+
+//unmarshalStruct fills each tagged field of a struct from the statements about subject
+func (model *Model) unmarshalStruct(subject *Node, value reflect.Value, path *structPath) error {
+	if err := path.enter(structPathKey{structType: value.Type(), node: subject.Key()}); err != nil {
+		return err
+	}
+	defer path.leave()
+
+	return model.taggedFields(value, func(field reflect.Value, fieldTag rdfFieldTag, predicate *Node) error {
+		if !isRepeated(field.Type()) {
+			object, err := model.readTarget(subject, predicate, fieldTag.language)
+			if err != nil {
+				return err
+			}
+			if object != nil {
+				defer object.Free()
+			}
+			return model.unmarshalObject(field, object, path)
+		}
+
+		var objects []*Node
+		var err error
+
+		if fieldTag.list {
+			if head := model.GetTarget(subject, predicate); head != nil {
+				objects, err = model.readList(head)
+				head.Free()
+			}
+		} else {
+			objects, err = model.readTargets(subject, predicate)
+			objects = filterLanguage(objects, fieldTag.language)
+		}
+
+		defer func() {
+			for _, object := range objects {
+				object.Free()
+			}
+		}()
+
+		if err != nil {
+			return err
+		}
+
+		if objects == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(objects), len(objects))
+		for i, object := range objects {
+			if err = model.unmarshalObject(slice.Index(i), object, path); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+
+		return nil
+	})
+}
+
+//unmarshalObject sets a Go value from the object of a statement
+func (model *Model) unmarshalObject(target reflect.Value, object *Node, path *structPath) error {
+	targetType := target.Type()
+
+	nestedType := targetType
+	if nestedType.Kind() == reflect.Ptr {
+		nestedType = nestedType.Elem()
+	}
+
+	if object == nil || !isNestedStruct(nestedType) {
+		return assignNode(target, object)
+	}
+
+	if object.IsLiteral() {
+		return fmt.Errorf("unable to read a struct from the literal %s", object.String())
+	}
+
+	if targetType.Kind() == reflect.Ptr {
+		element := reflect.New(nestedType)
+		if err := model.unmarshalStruct(object, element.Elem(), path); err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+	}
+
+	return model.unmarshalStruct(object, target, path)
+}
+
+//readTarget returns a target of subject + predicate, or nil if there is none
+//	If language is not empty the target is chosen from the literals with that language tag
+func (model *Model) readTarget(subject *Node, predicate *Node, language string) (*Node, error) {
+	if language == "" {
+		return model.GetTarget(subject, predicate), nil
+	}
+
+	objects, err := model.readTargets(subject, predicate)
+	objects = filterLanguage(objects, language)
+	if err != nil || len(objects) == 0 {
+		freeNodes(objects)
+		return nil, err
+	}
+
+	freeNodes(objects[1:])
+	return objects[0], nil
+}
+
+//readTargets returns every target of subject + predicate, ordered as for Node.Compare
+func (model *Model) readTargets(subject *Node, predicate *Node) ([]*Node, error) {
+	var objects []*Node
+
+	for object, err := range model.Targets(subject, predicate) {
+		if err != nil {
+			return objects, err
+		}
+		objects = append(objects, object)
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Compare(objects[j]) < 0
+	})
+
+	return objects, nil
+}
+
+//filterLanguage returns the literals in objects with the given language tag, freeing the other nodes
+//	objects is returned unchanged if language is empty
+func filterLanguage(objects []*Node, language string) []*Node {
+	if language == "" {
+		return objects
+	}
+
+	var matching []*Node
+	for _, object := range objects {
+		if object.IsLiteral() && strings.EqualFold(object.GetLiteralValueLanguage(), language) {
+			matching = append(matching, object)
+		} else {
+			object.Free()
+		}
+	}
+
+	return matching
+}
+
+//readList returns the members of the rdf:List starting at head
+func (model *Model) readList(head *Node) ([]*Node, error) {
+	first, err := NewNodeFromUriString(model.world, RdfFirst)
+	if err != nil {
+		return nil, err
+	}
+	defer first.Free()
+
+	rest, err := NewNodeFromUriString(model.world, RdfRest)
+	if err != nil {
+		return nil, err
+	}
+	defer rest.Free()
+
+	objects := []*Node{}
+	cell := head.clone()
+
+	for cell != nil && !(cell.IsResource() && cell.GetUriString() == RdfNil) {
+		if len(objects) == maxListLength {
+			cell.Free()
+			return objects, errors.New("rdf:List is too long or is cyclic")
+		}
+
+		object := model.GetTarget(cell, first)
+		if object == nil {
+			cell.Free()
+			return objects, fmt.Errorf("rdf:List cell %s has no rdf:first", cell.String())
+		}
+		objects = append(objects, object)
+
+		next := model.GetTarget(cell, rest)
+		cell.Free()
+		cell = next
+	}
+
+	if cell == nil {
+		return objects, errors.New("rdf:List is not terminated by rdf:nil")
+	}
+	cell.Free()
+
+	return objects, nil
+}
@@ -30,7 +30,7 @@ package golibrdf
 import "C"
 
 import (
-	"errors"
+	"context"
 	"runtime"
 	"unsafe"
 )
@@ -44,6 +44,14 @@ type Model struct {
 //NewModel constructs a new model backed by the provided storage
 //Refer to librdf_new_model documentation for available options
 func NewModel(world *World, storage *Storage, options string) (*Model, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &ModelError{Op: "make new model", Err: err}
+	}
+
+	if storage == nil || storage.librdf_storage == nil {
+		return nil, &ModelError{Op: "make new model", Err: ErrNilArgument}
+	}
+
 	cOptions := C.CString(options)
 	defer C.free(unsafe.Pointer(cOptions))
 
@@ -51,7 +59,7 @@ func NewModel(world *World, storage *Storage, options string) (*Model, error) {
 	model.librdf_model = C.librdf_new_model(world.librdf_world, storage.librdf_storage, cOptions)
 
 	if model.librdf_model == nil {
-		return nil, errors.New("Unable to make new model.  Call to librdf_new_model failed.")
+		return nil, &ModelError{Op: "make new model", Err: ErrNullResult}
 	}
 
 	model.world = world
@@ -64,12 +72,23 @@ func NewModel(world *World, storage *Storage, options string) (*Model, error) {
 
 //AddStatement adds the specified statement to the model
 func (model *Model) AddStatement(statement *Statement) (err error) {
-	C.librdf_model_add_statement(model.librdf_model, statement.librdf_statement)
+	if err = model.validate("add statement", checkStatement(statement)); err != nil {
+		return err
+	}
+
+	if retCode := C.librdf_model_add_statement(model.librdf_model, statement.librdf_statement); retCode != 0 {
+		return &ModelError{Op: "add statement", Err: ErrLibrdfFailed}
+	}
 	return nil
 }
 
 //ToString serializes the model to a string representation (RDFXML)
+//	An empty string is returned if the model has been freed
 func (model *Model) ToString() string {
+	if model.validate("serialize model") != nil {
+		return ""
+	}
+
 	cModelString := C.librdf_model_to_string(model.librdf_model, nil, nil, nil, nil)
 	defer C.free(unsafe.Pointer(cModelString))
 
@@ -78,79 +97,178 @@ func (model *Model) ToString() string {
 
 //FindTargets returns a channel used to iterate through a set of matched targets give a subject + predicate pair to match
 func (model *Model) FindTargets(subject *Node, predicate *Node, bufferSize int) chan *Node {
-	chanNode := make(chan *Node, bufferSize)
+	chanNode, _ := model.FindTargetsCtx(context.Background(), subject, predicate, bufferSize)
 
-	go func() {
-		iterator := C.librdf_model_get_targets(model.librdf_model, subject.librdf_node, predicate.librdf_node)
-		var librdfNode *C.librdf_node
-		var node *Node
-		var err error
-		var atEnd C.int
+	return chanNode
+}
 
-		if iterator != nil {
-			atEnd = C.librdf_iterator_end(iterator)
-			for atEnd == 0 {
+//FindTargetsCtx returns a channel used to iterate through a set of matched targets give a subject + predicate pair to match
+//	Iteration stops and the librdf iterator is freed when ctx is cancelled.  The returned error channel
+//	receives at most one error (including ctx.Err() on cancellation) and is closed once iteration has finished
+func (model *Model) FindTargetsCtx(ctx context.Context, subject *Node, predicate *Node, bufferSize int) (chan *Node, chan error) {
+	return seqToChannel(ctx, model.Targets(subject, predicate), bufferSize, (*Node).Free)
+}
 
-				librdfNode = (*C.librdf_node)(unsafe.Pointer(C.librdf_iterator_get_object(iterator)))
+//FindSources returns a channel used to iterate through a set of matched sources given a predicate + object pair to match
+func (model *Model) FindSources(predicate *Node, object *Node, bufferSize int) chan *Node {
+	chanNode, _ := seqToChannel(context.Background(), model.Sources(predicate, object), bufferSize, (*Node).Free)
 
-				if librdfNode == nil {
-					panic(errors.New("librdf returned null node"))
-				}
+	return chanNode
+}
 
-				if node, err = NewNode(model.world); err != nil {
-					panic(errors.New("Unable to create node"))
-				}
-				node.librdf_node = librdfNode
-				chanNode <- node
+//FindArcs returns a channel used to iterate through a set of matched arcs (predicates) given a subject + object pair to match
+func (model *Model) FindArcs(subject *Node, object *Node, bufferSize int) chan *Node {
+	chanNode, _ := seqToChannel(context.Background(), model.Arcs(subject, object), bufferSize, (*Node).Free)
 
-				C.librdf_iterator_next(iterator)
-				atEnd = C.librdf_iterator_end(iterator)
-			}
-			C.librdf_free_iterator(iterator)
-		}
+	return chanNode
+}
+
+//ArcsIn returns a channel used to iterate through the arcs (predicates) of statements that have the given node as their object
+func (model *Model) ArcsIn(node *Node, bufferSize int) chan *Node {
+	chanNode, _ := seqToChannel(context.Background(), model.IncomingArcs(node), bufferSize, (*Node).Free)
+
+	return chanNode
+}
 
-		close(chanNode)
-	}()
+//ArcsOut returns a channel used to iterate through the arcs (predicates) of statements that have the given node as their subject
+func (model *Model) ArcsOut(node *Node, bufferSize int) chan *Node {
+	chanNode, _ := seqToChannel(context.Background(), model.OutgoingArcs(node), bufferSize, (*Node).Free)
 
 	return chanNode
 }
 
+//HasArcIn returns true if the model contains a statement with the given property and the given node as its object
+func (model *Model) HasArcIn(node *Node, property *Node) bool {
+	if model.validate("find arc", checkNodes(node, property)) != nil {
+		return false
+	}
+	return C.librdf_model_has_arc_in(model.librdf_model, node.librdf_node, property.librdf_node) != 0
+}
+
+//HasArcOut returns true if the model contains a statement with the given node as its subject and the given property
+func (model *Model) HasArcOut(node *Node, property *Node) bool {
+	if model.validate("find arc", checkNodes(node, property)) != nil {
+		return false
+	}
+	return C.librdf_model_has_arc_out(model.librdf_model, node.librdf_node, property.librdf_node) != 0
+}
+
+//GetSource returns one source matching an arc (predicate) + target pair, or nil if there is no match
+//	The receiver owns the returned node
+func (model *Model) GetSource(arc *Node, target *Node) *Node {
+	if model.validate("get source", checkNodes(arc, target)) != nil {
+		return nil
+	}
+	return model.newNodeOrNil(C.librdf_model_get_source(model.librdf_model, arc.librdf_node, target.librdf_node))
+}
+
+//GetArc returns one arc (predicate) matching a source + target pair, or nil if there is no match
+//	The receiver owns the returned node
+func (model *Model) GetArc(source *Node, target *Node) *Node {
+	if model.validate("get arc", checkNodes(source, target)) != nil {
+		return nil
+	}
+	return model.newNodeOrNil(C.librdf_model_get_arc(model.librdf_model, source.librdf_node, target.librdf_node))
+}
+
+//GetTarget returns one target matching a source + arc (predicate) pair, or nil if there is no match
+//	The receiver owns the returned node
+func (model *Model) GetTarget(source *Node, arc *Node) *Node {
+	if model.validate("get target", checkNodes(source, arc)) != nil {
+		return nil
+	}
+	return model.newNodeOrNil(C.librdf_model_get_target(model.librdf_model, source.librdf_node, arc.librdf_node))
+}
+
+//newNodeOrNil wraps a new librdf node returned by the model, returning nil if librdf returned null
+func (model *Model) newNodeOrNil(librdf_node *C.librdf_node) *Node {
+	if librdf_node == nil {
+		return nil
+	}
+
+	return &Node{librdf_node: librdf_node, world: model.world}
+}
+
 //FindStatements creates a channel used to iterate the set of statements in the model that matched the given partial statement
 //	bufferSize indicates how many statements can be on the channel at one time
 func (model *Model) FindStatements(partialStatement *Statement, bufferSize int) chan *Statement {
-	chanStatement := make(chan *Statement, bufferSize)
-
-	go func() {
-		stream := C.librdf_model_find_statements(model.librdf_model, partialStatement.librdf_statement)
-		var librdfStatement *C.librdf_statement
-		var statement *Statement
-		var err error
-		var endOfStream C.int
-
-		if stream == nil {
-			panic(errors.New("librdf returned null stream"))
-		} else {
-			endOfStream = C.librdf_stream_end(stream)
-			for endOfStream == 0 {
-
-				if librdfStatement = C.librdf_stream_get_object(stream); librdfStatement == nil {
-					panic(errors.New("librdf returned null statement"))
-				}
-
-				if statement, err = NewStatement(model.world); err != nil {
-					panic(errors.New("Unable to create statement"))
-				}
-				statement.librdf_statement = librdfStatement
-				chanStatement <- statement
-
-				C.librdf_stream_next(stream)
-				endOfStream = C.librdf_stream_end(stream)
-			}
-			C.librdf_free_stream(stream)
-		}
+	chanStatement, _ := model.FindStatementsCtx(context.Background(), partialStatement, bufferSize)
+
+	return chanStatement
+}
+
+//FindStatementsCtx creates a channel used to iterate the set of statements in the model that matched the given partial statement
+//	Iteration stops and the librdf stream is freed when ctx is cancelled.  The returned error channel
+//	receives at most one error (including ctx.Err() on cancellation) and is closed once iteration has finished
+func (model *Model) FindStatementsCtx(ctx context.Context, partialStatement *Statement, bufferSize int) (chan *Statement, chan error) {
+	return seqToChannel(ctx, model.Statements(partialStatement), bufferSize, (*Statement).Free)
+}
+
+//SupportsContexts returns true if the storage backing the model supports contexts (named graphs)
+func (model *Model) SupportsContexts() bool {
+	if model.validate("check context support") != nil {
+		return false
+	}
+	return C.librdf_model_supports_contexts(model.librdf_model) != 0
+}
+
+//AddStatementWithContext adds the specified statement to the model within the given context
+func (model *Model) AddStatementWithContext(contextNode *Node, statement *Statement) error {
+	if err := model.validate("add statement to context", checkNodes(contextNode), checkStatement(statement)); err != nil {
+		return err
+	}
+
+	if retCode := C.librdf_model_context_add_statement(model.librdf_model, contextNode.librdf_node, statement.librdf_statement); retCode != 0 {
+		return &ModelError{Op: "add statement to context", Err: ErrLibrdfFailed}
+	}
+	return nil
+}
+
+//RemoveStatementWithContext removes the specified statement from the given context within the model
+func (model *Model) RemoveStatementWithContext(contextNode *Node, statement *Statement) error {
+	if err := model.validate("remove statement from context", checkNodes(contextNode), checkStatement(statement)); err != nil {
+		return err
+	}
+
+	if retCode := C.librdf_model_context_remove_statement(model.librdf_model, contextNode.librdf_node, statement.librdf_statement); retCode != 0 {
+		return &ModelError{Op: "remove statement from context", Err: ErrLibrdfFailed}
+	}
+	return nil
+}
+
+//RemoveContextStatements removes all statements in the given context from the model
+func (model *Model) RemoveContextStatements(contextNode *Node) error {
+	if err := model.validate("remove context statements", checkNodes(contextNode)); err != nil {
+		return err
+	}
+
+	if retCode := C.librdf_model_context_remove_statements(model.librdf_model, contextNode.librdf_node); retCode != 0 {
+		return &ModelError{Op: "remove context statements", Err: ErrLibrdfFailed}
+	}
+	return nil
+}
 
-		close(chanStatement)
-	}()
+//ContainsContext returns true if the model contains statements in the given context
+func (model *Model) ContainsContext(contextNode *Node) bool {
+	if model.validate("find context", checkNodes(contextNode)) != nil {
+		return false
+	}
+	return C.librdf_model_contains_context(model.librdf_model, contextNode.librdf_node) != 0
+}
+
+//GetContexts returns a channel used to iterate through the context nodes in the model
+//	bufferSize indicates how many nodes can be on the channel at one time
+func (model *Model) GetContexts(bufferSize int) chan *Node {
+	chanNode, _ := seqToChannel(context.Background(), model.Contexts(), bufferSize, (*Node).Free)
+
+	return chanNode
+}
+
+//FindStatementsInContext creates a channel used to iterate the set of statements in the given context that match the given partial statement
+//	If partialStatement is nil all statements in the context are returned
+//	bufferSize indicates how many statements can be on the channel at one time
+func (model *Model) FindStatementsInContext(partialStatement *Statement, contextNode *Node, bufferSize int) chan *Statement {
+	chanStatement, _ := seqToChannel(context.Background(), model.StatementsInContext(partialStatement, contextNode), bufferSize, (*Statement).Free)
 
 	return chanStatement
 }
@@ -159,6 +277,10 @@ func (model *Model) FindStatements(partialStatement *Statement, bufferSize int)
 func (model *Model) ContainsStatement(statement *Statement) bool {
 	var contains bool = false
 
+	if model.validate("find statement", checkStatement(statement)) != nil {
+		return contains
+	}
+
 	if retCode := C.librdf_model_contains_statement(model.librdf_model, statement.librdf_statement); retCode != 0 {
 		contains = true
 	}
@@ -168,130 +290,160 @@ func (model *Model) ContainsStatement(statement *Statement) bool {
 
 //RemoveStatement removes the specified statement from the model
 func (model *Model) RemoveStatement(statement *Statement) error {
+	if err := model.validate("remove statement", checkStatement(statement)); err != nil {
+		return err
+	}
+
 	if retCode := C.librdf_model_remove_statement(model.librdf_model, statement.librdf_statement); retCode != 0 {
-		return errors.New("Statement could not be removed")
+		return &ModelError{Op: "remove statement", Err: ErrLibrdfFailed}
 	}
 	return nil
 }
 
-func (model *Model) Load(uri *Uri) error {
-	if retCode := C.librdf_model_load(model.librdf_model, uri.librdf_uri, nil, nil, nil); retCode != 0 {
-		return errors.New("Failed to load model")
+//Size returns the number of statements in the model
+//	An error is returned if the storage backing the model is unable to count its statements
+func (model *Model) Size() (int, error) {
+	if err := model.validate("determine model size"); err != nil {
+		return 0, err
 	}
-	return nil
+
+	size := int(C.librdf_model_size(model.librdf_model))
+
+	if size < 0 {
+		return 0, &ModelError{Op: "determine model size", Err: ErrLibrdfFailed}
+	}
+
+	return size, nil
 }
 
-//ExecuteQueryToResultsChannel executes the given query and returns a channel used to read the results
-func (model *Model) ExecuteQueryToResultsChannel(query *Query, bufferSize int) (chan *QueryResultItem, error) {
-	var err error = nil
-	chanQueryResultItem := make(chan *QueryResultItem, bufferSize)
+//Count returns the number of statements in the model that match the given partial statement
+//	If partialStatement is nil all statements in the model are counted
+func (model *Model) Count(partialStatement *Statement) (int, error) {
+	var stream *C.librdf_stream
+
+	if err := model.validate("count statements"); err != nil {
+		return 0, err
+	}
 
-	cQueryString := C.CString(query.queryString)
-	if cQueryString != nil {
-		defer C.free(unsafe.Pointer(cQueryString))
+	if partialStatement == nil {
+		if size, err := model.Size(); err == nil {
+			return size, nil
+		}
+		stream = C.librdf_model_as_stream(model.librdf_model)
+	} else {
+		stream = C.librdf_model_find_statements(model.librdf_model, partialStatement.librdf_statement)
 	}
 
-	cName := C.CString(query.name)
-	if cName != nil {
-		defer C.free(unsafe.Pointer(cName))
+	if stream == nil {
+		return 0, &ModelError{Op: "count statements", Err: ErrNullResult}
 	}
+	defer C.librdf_free_stream(stream)
 
-	librdf_query := C.librdf_new_query(query.world.librdf_world, (*C.char)(unsafe.Pointer(cName)), nil, (*C.uchar)(unsafe.Pointer(cQueryString)), nil)
-	if librdf_query == nil {
-		return nil, errors.New("Failed to create new query")
+	count := 0
+	for C.librdf_stream_end(stream) == 0 {
+		count = count + 1
+		C.librdf_stream_next(stream)
 	}
 
-	results := C.librdf_model_query_execute(model.librdf_model, librdf_query)
+	return count, nil
+}
+
+//Sync flushes any statements held in memory by the storage backing the model to disk
+func (model *Model) Sync() error {
+	if err := model.validate("sync model"); err != nil {
+		return err
+	}
 
-	if results == nil {
-		err = errors.New("Error executing query")
+	if retCode := C.librdf_model_sync(model.librdf_model); retCode != 0 {
+		return &ModelError{Op: "sync model", Err: ErrLibrdfFailed}
 	}
+	return nil
+}
 
-	if C.librdf_query_results_finished(results) != 0 {
-		return nil, errors.New("Query returned no results")
+//Load adds the statements parsed from the data at the given URI to the model, guessing the syntax from the URI
+func (model *Model) Load(uri *Uri) error {
+	if err := model.validate("load model", checkUri(uri)); err != nil {
+		return err
 	}
 
-	go func() {
+	if retCode := C.librdf_model_load(model.librdf_model, uri.librdf_uri, nil, nil, nil); retCode != 0 {
+		return &ModelError{Op: "load model", Err: ErrLibrdfFailed}
+	}
+	return nil
+}
 
-		for C.librdf_query_results_finished(results) == 0 {
-			item := new(QueryResultItem)
+//ExecuteQueryToResultsChannel executes the given query and returns a channel used to read the results
+//	The query must produce variable bindings (a SELECT query).  A query that matches nothing returns a channel that is closed without any items.
+//	The channel must be read until it is closed, otherwise the goroutine sending the results and the librdf results
+//	are never freed.  Use ExecuteQueryToResultsChannelCtx to be able to stop reading early.
+func (model *Model) ExecuteQueryToResultsChannel(query *Query, bufferSize int) (chan *QueryResultItem, error) {
+	chanQueryResultItem, _, err := model.ExecuteQueryToResultsChannelCtx(context.Background(), query, bufferSize)
 
-			cBindingCount := C.librdf_query_results_get_bindings_count(results)
+	return chanQueryResultItem, err
+}
 
-			bindingCount := int(cBindingCount)
-			if bindingCount == 0 {
-				continue
-			}
+//ExecuteQueryToResultsChannelCtx executes the given query and returns a channel used to read the results
+//	Iteration stops and the librdf query results are freed when ctx is cancelled.  The returned error channel
+//	receives at most one error (including ctx.Err() on cancellation) and is closed once iteration has finished.
+//	A caller that stops reading must cancel ctx, otherwise the goroutine sending the results blocks and
+//	neither it nor the librdf results are ever freed.
+func (model *Model) ExecuteQueryToResultsChannelCtx(ctx context.Context, query *Query, bufferSize int) (chan *QueryResultItem, chan error, error) {
+	results, err := model.ExecuteQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
 
-			item.NameNodePairs = make([]NameNodePair, bindingCount, bindingCount)
+	if !results.IsBindings() {
+		results.Free()
+		return nil, nil, &QueryError{Op: "read query results", Language: query.name, Err: ErrResultForm}
+	}
 
-			for i := 0; i < bindingCount; i++ {
-				cName := C.librdf_query_results_get_binding_name(results, C.int(i))
-				librdf_node := C.librdf_query_results_get_binding_value(results, C.int(i))
+	// the results are freed once all rows have been read or iteration is cancelled
+	rows := func(yield func(*QueryResultItem, error) bool) {
+		defer results.Free()
 
-				item.NameNodePairs[i].Name = C.GoString(cName)
-				node := new(Node)
-				node.world = query.world
-				node.librdf_node = librdf_node
-				item.NameNodePairs[i].Node = node
+		for item, err := range results.Rows() {
+			if !yield(item, err) {
+				return
 			}
-
-			chanQueryResultItem <- item
-			C.librdf_query_results_next(results)
 		}
-		close(chanQueryResultItem)
-	}()
+	}
 
-	return chanQueryResultItem, err
+	chanQueryResultItem, chanErr := seqToChannel(ctx, rows, bufferSize, (*QueryResultItem).free)
+
+	return chanQueryResultItem, chanErr, nil
 }
 
 //ExecuteQueryToFormattedString executes a query and serializes the results to a string in the format provided
+//	Graph results are serialized using the serializer named by format, other results using the query results format named by format.
+//	The results are freed before returning.
 func (model *Model) ExecuteQueryToFormattedString(query *Query, format string) (string, error) {
-	var librdf_query *C.librdf_query
-
-	cQueryString := C.CString(query.queryString)
-	if cQueryString != nil {
-		defer C.free(unsafe.Pointer(cQueryString))
-	}
-
-	cName := C.CString(query.name)
-	if cName != nil {
-		defer C.free(unsafe.Pointer(cName))
-	}
-
 	cFormat := C.CString(format)
 	if cFormat != nil {
 		defer C.free(unsafe.Pointer(cFormat))
 	}
 
-	librdf_query = C.librdf_new_query(query.world.librdf_world, (*C.char)(unsafe.Pointer(cName)), nil, (*C.uchar)(unsafe.Pointer(cQueryString)), nil)
-
-	if librdf_query == nil {
-		return "", errors.New("Unable to create query for execution")
+	queryResults, err := model.ExecuteQuery(query)
+	if err != nil {
+		return "", err
 	}
+	defer queryResults.Free()
 
-	results := C.librdf_model_query_execute(model.librdf_model, librdf_query)
-
-	if results == nil {
-		return "", errors.New("Failed to execute query")
-	}
+	results := queryResults.librdf_query_results
 
 	var cFormattedString *C.uchar
-	isBindings := C.librdf_query_results_is_bindings(results)
-	isBoolean := C.librdf_query_results_is_boolean(results)
-
-	if isBindings == 0 || isBoolean == 0 {
+	if !queryResults.IsGraph() {
 		cFormattedString = C.librdf_query_results_to_string2(results, (*C.char)(unsafe.Pointer(cFormat)), nil, nil, nil)
 	} else {
 		var serializer *C.librdf_serializer
 		if serializer = C.librdf_new_serializer(query.world.librdf_world, (*C.char)(unsafe.Pointer(cFormat)), nil, nil); serializer == nil {
-			return "", errors.New("Failed to build serializer")
+			return "", &SerializerError{Op: "create serializer", Syntax: format, Err: ErrNullResult}
 		}
 		defer C.librdf_free_serializer(serializer)
 
 		var stream *C.librdf_stream
 		if stream = C.librdf_query_results_as_stream(results); stream == nil {
-			return "", errors.New("Failed to build stream from results")
+			return "", &QueryError{Op: "read query results", Language: query.name, Err: ErrNullResult}
 		}
 		defer C.librdf_free_stream(stream)
 
@@ -299,7 +451,7 @@ func (model *Model) ExecuteQueryToFormattedString(query *Query, format string) (
 	}
 
 	if cFormattedString == nil {
-		return "", errors.New("Failed to build formatted string from results")
+		return "", &QueryError{Op: "format query results", Language: query.name, Err: ErrLibrdfFailed}
 	}
 	defer C.free(unsafe.Pointer(cFormattedString))
 
@@ -313,9 +465,24 @@ func (model *Model) ExecuteQueryToFormattedString(query *Query, format string) (
 //  however it is important to explicitly call Free to avoid issues that may result
 //  from freeing resources in an unexpected order
 func (model *Model) Free() {
-	if model.librdf_model != nil {
+	if model != nil && model.librdf_model != nil {
 		C.librdf_free_model(model.librdf_model)
 		model.librdf_model = nil
 	}
 	return
 }
+
+//validate returns a *ModelError for op if the model has been freed or any of the argument checks failed
+func (model *Model) validate(op string, argumentErrors ...error) error {
+	if model == nil || model.librdf_model == nil {
+		return &ModelError{Op: op, Err: ErrFreed}
+	}
+
+	for _, err := range argumentErrors {
+		if err != nil {
+			return &ModelError{Op: op, Err: err}
+		}
+	}
+
+	return nil
+}
@@ -30,7 +30,9 @@ package golibrdf
 import "C"
 
 import (
-	"errors"
+	"fmt"
+	"runtime"
+	"strings"
 	"unsafe"
 )
 
@@ -49,6 +51,14 @@ func NewNode(world *World) (*Node, error) {
 
 //NewNode constructs a new node from a specified URI
 func NewNodeFromUri(world *World, uri *Uri) (*Node, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &NodeError{Op: "create resource node", Err: err}
+	}
+
+	if err := checkUri(uri); err != nil {
+		return nil, &NodeError{Op: "create resource node", Err: err}
+	}
+
 	node, err := NewNode(world)
 
 	if err != nil {
@@ -57,11 +67,19 @@ func NewNodeFromUri(world *World, uri *Uri) (*Node, error) {
 
 	node.librdf_node = C.librdf_new_node_from_uri(world.librdf_world, uri.librdf_uri)
 
+	if node.librdf_node == nil {
+		return nil, &NodeError{Op: "create resource node", Err: ErrNullResult}
+	}
+
 	return node, nil
 }
 
 //NewNode constructs a new node from a string literal
 func NewNodeFromLiteral(world *World, literal string) (*Node, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &NodeError{Op: "create literal node", Err: err}
+	}
+
 	node, err := NewNode(world)
 
 	if err != nil {
@@ -73,11 +91,46 @@ func NewNodeFromLiteral(world *World, literal string) (*Node, error) {
 
 	node.librdf_node = C.librdf_new_node_from_literal(world.librdf_world, (*C.uchar)(unsafe.Pointer(cLiteralString)), nil, 0)
 
+	if node.librdf_node == nil {
+		return nil, &NodeError{Op: "create literal node", Err: ErrNullResult}
+	}
+
+	return node, nil
+}
+
+//NewNodeFromLiteralWithLanguage constructs a new node from a string literal with a language tag
+func NewNodeFromLiteralWithLanguage(world *World, literal string, language string) (*Node, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &NodeError{Op: "create literal node with language", Err: err}
+	}
+
+	node, err := NewNode(world)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cLiteralString := C.CString(literal)
+	defer C.free(unsafe.Pointer(cLiteralString))
+
+	cLanguageString := C.CString(language)
+	defer C.free(unsafe.Pointer(cLanguageString))
+
+	node.librdf_node = C.librdf_new_node_from_literal(world.librdf_world, (*C.uchar)(unsafe.Pointer(cLiteralString)), cLanguageString, 0)
+
+	if node.librdf_node == nil {
+		return nil, &NodeError{Op: "create literal node with language", Err: ErrNullResult}
+	}
+
 	return node, nil
 }
 
 //NewNode constructs a new node from an xml literal
 func NewNodeFromXmlLiteral(world *World, xmlLiteral string, xmlLanguage string) (*Node, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &NodeError{Op: "create XML literal node", Err: err}
+	}
+
 	node, err := NewNode(world)
 
 	if err != nil {
@@ -88,10 +141,102 @@ func NewNodeFromXmlLiteral(world *World, xmlLiteral string, xmlLanguage string)
 	defer C.free(unsafe.Pointer(cLiteralString))
 
 	cXmlLangString := C.CString(xmlLanguage)
-	defer C.free(unsafe.Pointer(cLiteralString))
+	defer C.free(unsafe.Pointer(cXmlLangString))
 
 	node.librdf_node = C.librdf_new_node_from_literal(world.librdf_world, (*C.uchar)(unsafe.Pointer(cLiteralString)), cXmlLangString, 1)
 
+	if node.librdf_node == nil {
+		return nil, &NodeError{Op: "create XML literal node", Err: ErrNullResult}
+	}
+
+	return node, nil
+}
+
+//NewNodeFromTypedLiteral constructs a new node from a literal string with a datatype given by datatypeUri
+func NewNodeFromTypedLiteral(world *World, literal string, datatypeUri *Uri) (*Node, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &NodeError{Op: "create typed literal node", Err: err}
+	}
+
+	node, err := NewNode(world)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var datatypeUriPtr *C.librdf_uri
+	if datatypeUri != nil {
+		datatypeUriPtr = datatypeUri.librdf_uri
+	}
+
+	cLiteralString := C.CString(literal)
+	defer C.free(unsafe.Pointer(cLiteralString))
+
+	node.librdf_node = C.librdf_new_node_from_typed_literal(world.librdf_world, (*C.uchar)(unsafe.Pointer(cLiteralString)), nil, datatypeUriPtr)
+
+	if node.librdf_node == nil {
+		return nil, &NodeError{Op: "create typed literal node", Err: ErrNullResult}
+	}
+
+	return node, nil
+}
+
+//NewNodeFromTypedLiteralUriString constructs a new node from a literal string with a datatype given by a URI string
+func NewNodeFromTypedLiteralUriString(world *World, literal string, datatypeUriString string) (*Node, error) {
+	var node *Node
+	var err error
+	var datatypeUri *Uri
+
+	if datatypeUri, err = newUriWithoutFinaliser(world, datatypeUriString); err == nil {
+		defer datatypeUri.Free()
+		node, err = NewNodeFromTypedLiteral(world, literal, datatypeUri)
+	}
+
+	return node, err
+}
+
+//NewBlankNode constructs a new blank node with an identifier generated by librdf
+func NewBlankNode(world *World) (*Node, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &NodeError{Op: "create blank node", Err: err}
+	}
+
+	node, err := NewNode(world)
+
+	if err != nil {
+		return nil, err
+	}
+
+	node.librdf_node = C.librdf_new_node_from_blank_identifier(world.librdf_world, nil)
+
+	if node.librdf_node == nil {
+		return nil, &NodeError{Op: "create blank node", Err: ErrNullResult}
+	}
+
+	return node, nil
+}
+
+//NewBlankNodeFromId constructs a new blank node with the specified identifier
+func NewBlankNodeFromId(world *World, id string) (*Node, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &NodeError{Op: "create blank node from identifier", Err: err}
+	}
+
+	node, err := NewNode(world)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cId := C.CString(id)
+	defer C.free(unsafe.Pointer(cId))
+
+	node.librdf_node = C.librdf_new_node_from_blank_identifier(world.librdf_world, (*C.uchar)(unsafe.Pointer(cId)))
+
+	if node.librdf_node == nil {
+		return nil, &NodeError{Op: "create blank node from identifier", Err: ErrNullResult}
+	}
+
 	return node, nil
 }
 
@@ -109,16 +254,81 @@ func NewNodeFromUriString(world *World, uriString string) (*Node, error) {
 	return node, err
 }
 
+//ParseNode constructs a new node from a term in N-Triples syntax
+//	for example <http://example.org/>, "literal"@en, "1"^^<http://www.w3.org/2001/XMLSchema#int> or _:b0
+func ParseNode(world *World, text string) (*Node, error) {
+	term, err := parseNTriplesTerm(text)
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse node from %q: %w", text, err)
+	}
+
+	switch term.kind {
+	case ntriplesTermIri:
+		return NewNodeFromUriString(world, term.value)
+	case ntriplesTermBlank:
+		return NewBlankNodeFromId(world, term.value)
+	}
+
+	if term.language != "" {
+		return NewNodeFromLiteralWithLanguage(world, term.value, term.language)
+	}
+
+	if term.datatype != "" {
+		return NewNodeFromTypedLiteralUriString(world, term.value, term.datatype)
+	}
+
+	return NewNodeFromLiteral(world, term.value)
+}
+
+//String returns the N-Triples representation of the node, or an empty string if the node has no term
+func (node *Node) String() string {
+	return string(node.Key())
+}
+
+//MarshalText encodes the node as a term in N-Triples syntax
+func (node *Node) MarshalText() ([]byte, error) {
+	if node.isUnbound() {
+		return nil, &NodeError{Op: "marshal node", Err: ErrFreed}
+	}
+
+	return []byte(node.Key()), nil
+}
+
+//UnmarshalText decodes a term in N-Triples syntax into the node
+//	The node must have been constructed with NewNode so that it is associated with a World
+//	Any term previously held by the node is freed
+func (node *Node) UnmarshalText(text []byte) error {
+	if node.world == nil {
+		return &NodeError{Op: "unmarshal node", Err: ErrWorldClosed}
+	}
+
+	parsedNode, err := ParseNode(node.world, string(text))
+
+	if err != nil {
+		return err
+	}
+
+	node.Free()
+	node.librdf_node = parsedNode.librdf_node
+
+	return nil
+}
+
 //ToString returns a string representation of the node
 func (node *Node) ToString() (string, error) {
 	var stringPointer unsafe.Pointer
 	var length C.size_t
 
+	if err := checkNodes(node); err != nil {
+		return "", &NodeError{Op: "write node", Err: err}
+	}
+
 	raptorWorld := node.world.GetRaptorWorld()
 	stream := C.raptor_new_iostream_to_string(raptorWorld, &stringPointer, &length, nil)
 
 	if stream == nil {
-		return "", errors.New("Unable to obtain raptor stream")
+		return "", &NodeError{Op: "write node", Err: ErrNullResult}
 	}
 
 	defer C.free(unsafe.Pointer(stringPointer))
@@ -165,6 +375,19 @@ func (node *Node) IsBlank() bool {
 	return (isBlank != 0) 
 }
 
+//GetBlankIdentifier returns the identifier for the node.  (Only appropriate if the node is a blank type)
+func (node *Node) GetBlankIdentifier() string {
+	var identifier string
+
+	value := C.librdf_node_get_blank_identifier(node.librdf_node)
+
+	if value != nil {
+		identifier = C.GoString(((*C.char)(unsafe.Pointer(value))))
+	}
+
+	return identifier
+}
+
 //GetLiteralValue returns the literal string for the node.  (Only appropriate if the node is a literal type)
 func (node *Node) GetLiteralValue() string {
 	var literalString string
@@ -191,6 +414,121 @@ func (node *Node) GetLiteralValueLanguage() string {
 	return languageString
 }
 
+//GetLiteralValueDatatypeUri returns a copy of the datatype URI of a typed literal node, or nil if the node has no datatype
+func (node *Node) GetLiteralValueDatatypeUri() *Uri {
+	var uri *Uri
+
+	if datatypeUri := C.librdf_node_get_literal_value_datatype_uri(node.librdf_node); datatypeUri != nil {
+		uri = new(Uri)
+		uri.librdf_uri = C.librdf_new_uri_from_uri(datatypeUri)
+
+		runtime.SetFinalizer(uri, (*Uri).Free)
+	}
+
+	return uri
+}
+
+//IsWellFormedXML returns true if the node is a literal holding well formed XML
+func (node *Node) IsWellFormedXML() bool {
+	isWellFormedXML := C.librdf_node_get_literal_value_is_wf_xml(node.librdf_node)
+
+	return (isWellFormedXML != 0)
+}
+
+//NodeKey is a stable string identifying a node's term that may be used as a map key
+//	Nodes that are equal have the same key and nodes that are not equal have different keys
+type NodeKey string
+
+//Equals compares 2 nodes and returns true if they represent the same term
+func (node *Node) Equals(other *Node) bool {
+	if node == nil || other == nil || node.librdf_node == nil || other.librdf_node == nil {
+		return node.isUnbound() && other.isUnbound()
+	}
+
+	return C.librdf_node_equals(node.librdf_node, other.librdf_node) != 0
+}
+
+//Key returns a NodeKey for the node, built from the N-Triples representation of its term
+//	A nil node returns an empty key
+func (node *Node) Key() NodeKey {
+	switch {
+	case node.isUnbound():
+		return ""
+	case node.IsBlank():
+		return NodeKey(formatNTriplesBlank(node.GetBlankIdentifier()))
+	case node.IsResource():
+		return NodeKey(formatNTriplesIri(node.GetUriString()))
+	}
+
+	term := node.literalParts()
+
+	return NodeKey(formatNTriplesLiteral(term.lexicalForm, term.language, term.datatype))
+}
+
+//Compare compares 2 nodes using the SPARQL ORDER BY ordering, extended to a total order
+// Unbound (nil) nodes sort first, followed by blank nodes, resources and then literals
+// Numeric, boolean and dateTime literals are compared by value, other literals by lexical form,
+// with language and datatype used to order literals that would otherwise be equal
+// Returns <0 if the node is less than other
+// Returns >0 if the node is greater than other
+// Returns 0 if the nodes are equal
+func (node *Node) Compare(other *Node) int {
+	if rank, otherRank := node.orderRank(), other.orderRank(); rank != otherRank {
+		return rank - otherRank
+	}
+
+	switch {
+	case node.isUnbound():
+		return 0
+	case node.IsBlank():
+		return strings.Compare(node.GetBlankIdentifier(), other.GetBlankIdentifier())
+	case node.IsResource():
+		return strings.Compare(node.GetUriString(), other.GetUriString())
+	}
+
+	return compareLiterals(node.literalParts(), other.literalParts())
+}
+
+//isUnbound returns true if there is no librdf node to represent a term
+func (node *Node) isUnbound() bool {
+	return node == nil || node.librdf_node == nil
+}
+
+//orderRank returns the position of the node's kind in the SPARQL ORDER BY ordering
+func (node *Node) orderRank() int {
+	switch {
+	case node.isUnbound():
+		return 0
+	case node.IsBlank():
+		return 1
+	case node.IsResource():
+		return 2
+	}
+
+	return 3
+}
+
+//literalParts returns the lexical form, language and datatype URI string of a literal node
+func (node *Node) literalParts() literalTerm {
+	var datatype string
+
+	if datatypeUri := node.GetLiteralValueDatatypeUri(); datatypeUri != nil {
+		datatype = datatypeUri.ToString()
+		datatypeUri.Free()
+	}
+
+	return literalTerm{lexicalForm: node.GetLiteralValue(), language: node.GetLiteralValueLanguage(), datatype: datatype}
+}
+
+//clone returns a new node holding a copy of the node's term, or nil if the node is unbound
+func (node *Node) clone() *Node {
+	if node.isUnbound() {
+		return nil
+	}
+
+	return &Node{librdf_node: C.librdf_new_node_from_node(node.librdf_node), world: node.world}
+}
+
 //Free cleans up memory resources held by the Node
 //	Free will be automatically called when Node instances are garbage collected
 //  however it is important to explicitly call Free to avoid issues that may result
@@ -0,0 +1,290 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information
+* on the Redland libraries that this package wraps
+*
+* This golibrdf package is:
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+*
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+ */
+
+package golibrdf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//formatNTriplesIri formats an IRI as an N-Triples IRI reference
+func formatNTriplesIri(iri string) string {
+	var builder strings.Builder
+
+	builder.WriteString("<")
+	for _, r := range iri {
+		switch {
+		case r <= 0x20, strings.ContainsRune("<>\"{}|^`\\", r):
+			fmt.Fprintf(&builder, "\\u%04X", r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteString(">")
+
+	return builder.String()
+}
+
+//formatNTriplesLiteral formats a literal in N-Triples syntax
+//	language and datatype may be empty, datatype is ignored when a language is given
+func formatNTriplesLiteral(lexicalForm string, language string, datatype string) string {
+	var builder strings.Builder
+
+	builder.WriteString("\"")
+	for _, r := range lexicalForm {
+		switch r {
+		case '"':
+			builder.WriteString("\\\"")
+		case '\\':
+			builder.WriteString("\\\\")
+		case '\n':
+			builder.WriteString("\\n")
+		case '\r':
+			builder.WriteString("\\r")
+		case '\t':
+			builder.WriteString("\\t")
+		case '\b':
+			builder.WriteString("\\b")
+		case '\f':
+			builder.WriteString("\\f")
+		default:
+			if r < 0x20 || r == 0x7F {
+				fmt.Fprintf(&builder, "\\u%04X", r)
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+	builder.WriteString("\"")
+
+	if language != "" {
+		builder.WriteString("@" + language)
+	} else if datatype != "" {
+		builder.WriteString("^^" + formatNTriplesIri(datatype))
+	}
+
+	return builder.String()
+}
+
+//formatNTriplesBlank formats a blank node identifier as an N-Triples blank node label
+func formatNTriplesBlank(id string) string {
+	return "_:" + id
+}
+
+//kinds of term that may be parsed from N-Triples syntax
+const (
+	ntriplesTermIri = iota
+	ntriplesTermBlank
+	ntriplesTermLiteral
+)
+
+//ntriplesTerm holds the parts of a term parsed from N-Triples syntax
+type ntriplesTerm struct {
+	kind     int
+	value    string
+	language string
+	datatype string
+}
+
+//parseNTriplesTerm parses a single IRI, blank node or literal term in N-Triples syntax
+func parseNTriplesTerm(text string) (ntriplesTerm, error) {
+	var term ntriplesTerm
+	var rest string
+	var err error
+
+	text = strings.TrimSpace(text)
+
+	switch {
+	case strings.HasPrefix(text, "<"):
+		term.kind = ntriplesTermIri
+		term.value, rest, err = parseNTriplesIri(text)
+	case strings.HasPrefix(text, "_:"):
+		term.kind = ntriplesTermBlank
+		term.value, rest, err = parseNTriplesBlank(text)
+	case strings.HasPrefix(text, "\""):
+		term.kind = ntriplesTermLiteral
+		term.value, rest, err = parseNTriplesString(text)
+		if err == nil {
+			term.language, term.datatype, rest, err = parseNTriplesLiteralSuffix(rest)
+		}
+	default:
+		err = errors.New("expected an IRI, blank node or literal")
+	}
+
+	if err == nil && rest != "" {
+		err = fmt.Errorf("unexpected text %q after term", rest)
+	}
+
+	return term, err
+}
+
+//parseNTriplesIri parses an IRI reference from the start of text, returning the IRI and the remaining text
+func parseNTriplesIri(text string) (string, string, error) {
+	var builder strings.Builder
+
+	for i := 1; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		switch {
+		case r == '>':
+			return builder.String(), text[i+1:], nil
+		case r == '\\':
+			unescaped, length, err := parseNTriplesUnicodeEscape(text[i:])
+			if err != nil {
+				return "", "", err
+			}
+			builder.WriteRune(unescaped)
+			i += length
+			continue
+		case r <= 0x20, strings.ContainsRune("<\"{}|^`", r):
+			return "", "", fmt.Errorf("invalid character %q in IRI", r)
+		}
+
+		builder.WriteRune(r)
+		i += size
+	}
+
+	return "", "", errors.New("unterminated IRI")
+}
+
+//parseNTriplesBlank parses a blank node label from the start of text, returning the identifier and the remaining text
+func parseNTriplesBlank(text string) (string, string, error) {
+	end := 2
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.') {
+			break
+		}
+		end += size
+	}
+
+	// a blank node label may not end with a '.'
+	for end > 2 && text[end-1] == '.' {
+		end--
+	}
+
+	if end == 2 {
+		return "", "", errors.New("empty blank node label")
+	}
+
+	return text[2:end], text[end:], nil
+}
+
+//parseNTriplesString parses a quoted string from the start of text, returning the unescaped string and the remaining text
+func parseNTriplesString(text string) (string, string, error) {
+	var builder strings.Builder
+
+	for i := 1; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		switch r {
+		case '"':
+			return builder.String(), text[i+1:], nil
+		case '\n', '\r':
+			return "", "", errors.New("unescaped line break in string")
+		case '\\':
+			if i+1 >= len(text) {
+				return "", "", errors.New("unterminated escape sequence in string")
+			}
+			if unescaped, ok := ntriplesStringEscapes[text[i+1]]; ok {
+				builder.WriteByte(unescaped)
+				i += 2
+				continue
+			}
+			unescaped, length, err := parseNTriplesUnicodeEscape(text[i:])
+			if err != nil {
+				return "", "", err
+			}
+			builder.WriteRune(unescaped)
+			i += length
+			continue
+		}
+
+		builder.WriteRune(r)
+		i += size
+	}
+
+	return "", "", errors.New("unterminated string")
+}
+
+//characters that may follow a backslash in an N-Triples string and the characters they represent
+var ntriplesStringEscapes = map[byte]byte{
+	't': '\t', 'b': '\b', 'n': '\n', 'r': '\r', 'f': '\f', '"': '"', '\'': '\'', '\\': '\\',
+}
+
+//parseNTriplesLiteralSuffix parses an optional language tag or datatype following a literal string
+func parseNTriplesLiteralSuffix(text string) (string, string, string, error) {
+	switch {
+	case strings.HasPrefix(text, "@"):
+		end := 1
+		for end < len(text) && (isAsciiLetter(text[end]) || end > 1 && (text[end] == '-' || text[end] >= '0' && text[end] <= '9')) {
+			end++
+		}
+		language := text[1:end]
+		if language == "" || strings.HasSuffix(language, "-") || strings.Contains(language, "--") || !isAsciiLetter(language[0]) {
+			return "", "", "", fmt.Errorf("invalid language tag %q", language)
+		}
+		return language, "", text[end:], nil
+	case strings.HasPrefix(text, "^^"):
+		if !strings.HasPrefix(text[2:], "<") {
+			return "", "", "", errors.New("expected an IRI for the literal datatype")
+		}
+		datatype, rest, err := parseNTriplesIri(text[2:])
+		return "", datatype, rest, err
+	}
+
+	return "", "", text, nil
+}
+
+//parseNTriplesUnicodeEscape parses a \uXXXX or \UXXXXXXXX escape from the start of text, returning the rune and the escape length
+func parseNTriplesUnicodeEscape(text string) (rune, int, error) {
+	length := 0
+
+	switch {
+	case strings.HasPrefix(text, "\\u"):
+		length = 6
+	case strings.HasPrefix(text, "\\U"):
+		length = 10
+	default:
+		return 0, 0, errors.New("invalid escape sequence")
+	}
+
+	if len(text) < length {
+		return 0, 0, errors.New("incomplete unicode escape sequence")
+	}
+
+	code, err := strconv.ParseUint(text[2:length], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, 0, fmt.Errorf("invalid unicode escape sequence %q", text[:length])
+	}
+
+	return rune(code), length, nil
+}
+
+//isAsciiLetter returns true if c is an ASCII letter
+func isAsciiLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,158 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//ParameterizedQuery is a SPARQL query template containing ?var or $var placeholders that are bound to values
+//	Bound values are written into the query text as SPARQL terms, with literals escaped, so that values
+//	cannot change the structure of the query.  Placeholders within strings, IRIs and comments are ignored.
+//
+//	Bound variables are replaced where they match values in the query pattern.  Occurrences that name
+//	results, such as those in a SELECT projection, a GROUP BY, ORDER BY or HAVING clause, a VALUES block
+//	or following AS, are left as variables so that the query remains valid.  A projected variable that
+//	is bound is therefore unbound in the query results.
+type ParameterizedQuery struct {
+	world    *World
+	name     string
+	parts    []sparqlTemplatePart
+	bindings map[string]string
+}
+
+//NewParameterizedQuery constructs a new parameterized query given a name indicating the query type
+//	and a query template.  The template must use SPARQL syntax.
+func NewParameterizedQuery(world *World, name string, template string) (*ParameterizedQuery, error) {
+	parts, err := parseSparqlTemplate(template)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse query template: %w", err)
+	}
+
+	query := ParameterizedQuery{world: world, name: name, parts: parts, bindings: make(map[string]string)}
+
+	return &query, nil
+}
+
+//Variables returns the names of the variables that appear in the query template, without their ? or $ prefix
+func (query *ParameterizedQuery) Variables() []string {
+	var variables []string
+	seen := make(map[string]bool)
+
+	for _, part := range query.parts {
+		if part.variable != "" && !seen[part.variable] {
+			seen[part.variable] = true
+			variables = append(variables, part.variable)
+		}
+	}
+
+	return variables
+}
+
+//Bind binds a variable to a node, replacing any existing binding
+//	variable may be given with or without its ? or $ prefix.  The node must be a resource or a literal;
+//	blank nodes cannot be bound as a blank node label in a query acts as a variable.
+func (query *ParameterizedQuery) Bind(variable string, node *Node) error {
+	if node == nil || node.isUnbound() {
+		return fmt.Errorf("Unable to bind variable ?%s.  Node is nil.", variable)
+	}
+
+	var term string
+	var err error
+
+	switch {
+	case node.IsBlank():
+		return fmt.Errorf("Unable to bind variable ?%s.  Blank nodes cannot be bound.", variable)
+	case node.IsResource():
+		term, err = formatSparqlIri(node.GetUriString())
+	default:
+		parts := node.literalParts()
+		term, err = formatSparqlLiteral(parts.lexicalForm, parts.language, parts.datatype)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Unable to bind variable ?%s: %w", variable, err)
+	}
+
+	return query.bindTerm(variable, term)
+}
+
+//BindValue binds a variable to a literal converted from a Go value, replacing any existing binding
+//	Values are converted as for NewNodeFromValue.  A *Node value is bound as for Bind.
+func (query *ParameterizedQuery) BindValue(variable string, value interface{}) error {
+	if node, isNode := value.(*Node); isNode {
+		return query.Bind(variable, node)
+	}
+
+	lexicalForm, datatype, err := formatLiteralValue(value)
+	if err != nil {
+		return fmt.Errorf("Unable to bind variable ?%s: %w", variable, err)
+	}
+
+	term, err := formatSparqlLiteral(lexicalForm, "", datatype)
+	if err != nil {
+		return fmt.Errorf("Unable to bind variable ?%s: %w", variable, err)
+	}
+
+	return query.bindTerm(variable, term)
+}
+
+//Unbind removes any binding for a variable so that it remains a variable in the query
+func (query *ParameterizedQuery) Unbind(variable string) {
+	delete(query.bindings, strings.TrimLeft(variable, "?$"))
+}
+
+//QueryString returns the query text with bound variables replaced by their values
+func (query *ParameterizedQuery) QueryString() string {
+	var builder strings.Builder
+
+	for _, part := range query.parts {
+		if term, isBound := query.bindings[part.variable]; isBound && part.isBindable {
+			builder.WriteString(term)
+		} else {
+			builder.WriteString(part.text)
+		}
+	}
+
+	return builder.String()
+}
+
+//Query constructs a Query from the query text with bound variables replaced by their values
+func (query *ParameterizedQuery) Query() (Query, error) {
+	return NewQuery(query.world, query.name, query.QueryString())
+}
+
+//bindTerm records the SPARQL term a variable is bound to
+func (query *ParameterizedQuery) bindTerm(variable string, term string) error {
+	name := strings.TrimLeft(variable, "?$")
+
+	for _, part := range query.parts {
+		if part.variable == name && part.isBindable {
+			query.bindings[name] = term
+			return nil
+		}
+	}
+
+	return errors.New("Unable to bind variable ?" + name + ".  Variable does not appear in the query pattern.")
+}
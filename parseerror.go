@@ -0,0 +1,143 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+import (
+	"errors"
+	"fmt"
+)
+
+//ParseError is returned when RDF data or a query cannot be parsed
+//	Messages holds every error and warning logged while parsing, in the order they were logged
+type ParseError struct {
+	Summary  string
+	Messages []LogMessage
+}
+
+//newParseError builds a ParseError from the messages logged during a failed parse
+func newParseError(summary string, messages []LogMessage) *ParseError {
+	parseError := ParseError{Summary: summary}
+
+	for _, message := range messages {
+		if message.Level >= LogLevelWarn {
+			parseError.Messages = append(parseError.Messages, message)
+		}
+	}
+
+	return &parseError
+}
+
+//Error returns the summary along with the first error logged and a count of any others
+func (parseError *ParseError) Error() string {
+	errorMessages := parseError.Errors()
+	if len(errorMessages) == 0 {
+		return parseError.Summary
+	}
+
+	text := fmt.Sprintf("%s: %s", parseError.Summary, errorMessages[0])
+	if len(errorMessages) > 1 {
+		text += fmt.Sprintf(" (and %d more errors)", len(errorMessages)-1)
+	}
+
+	return text
+}
+
+//Is reports whether target is ErrSyntax, so that errors.Is can identify a parse failure through any wrapping error
+func (parseError *ParseError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+//FetchError is returned when the data at a URI cannot be retrieved for parsing, such as a missing file or a failed request
+//	Messages holds every error and warning logged while the data was being retrieved
+type FetchError struct {
+	Uri      string
+	Messages []LogMessage
+}
+
+//newParseFailure returns the error for a failed parse of the data at uri
+//	raptor reports errors in the data with their location, so a failure without any located error is
+//	reported as a *FetchError and any other failure as a *ParseError
+func newParseFailure(summary string, uri string, messages []LogMessage) error {
+	for _, message := range messages {
+		if message.Level >= LogLevelError && (message.Line > 0 || message.Column > 0) {
+			return newParseError(summary, messages)
+		}
+	}
+
+	fetchError := FetchError{Uri: uri}
+	for _, message := range messages {
+		if message.Level >= LogLevelWarn {
+			fetchError.Messages = append(fetchError.Messages, message)
+		}
+	}
+
+	return &fetchError
+}
+
+//Error returns the URI along with the first error logged, if any
+func (fetchError *FetchError) Error() string {
+	for _, message := range fetchError.Messages {
+		if message.Level >= LogLevelError {
+			return formatOpError("retrieve data", "URI", fetchError.Uri, errors.New(message.Message))
+		}
+	}
+
+	return formatOpError("retrieve data", "URI", fetchError.Uri, nil)
+}
+
+//Is reports whether target is ErrFetch, so that errors.Is can identify a fetch failure through any wrapping error
+func (fetchError *FetchError) Is(target error) bool {
+	return target == ErrFetch
+}
+
+//hasErrors returns true if any of the messages were logged at error level or above
+func hasErrors(messages []LogMessage) bool {
+	for _, message := range messages {
+		if message.Level >= LogLevelError {
+			return true
+		}
+	}
+	return false
+}
+
+//Errors returns the messages logged at error level or above
+func (parseError *ParseError) Errors() []LogMessage {
+	return parseError.filter(func(level LogLevel) bool { return level >= LogLevelError })
+}
+
+//Warnings returns the messages logged at warning level
+func (parseError *ParseError) Warnings() []LogMessage {
+	return parseError.filter(func(level LogLevel) bool { return level == LogLevelWarn })
+}
+
+//filter returns the messages with a level matching the given test
+func (parseError *ParseError) filter(test func(level LogLevel) bool) []LogMessage {
+	var messages []LogMessage
+
+	for _, message := range parseError.Messages {
+		if test(message.Level) {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
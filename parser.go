@@ -26,27 +26,64 @@ package golibrdf
 // #include <stdlib.h>
 // #include <string.h>
 // #include <strings.h>
+// #include <stdint.h>
 // #include <librdf.h>
+//
+// extern void golibrdfParserNamespace(uintptr_t handle, char *prefix, char *uri);
+//
+// typedef struct {
+//   librdf_model *model;
+//   uintptr_t parser;
+//   int failed;
+// } golibrdf_parse_target;
+//
+// static void golibrdf_add_statement_to_model(void *user_data, raptor_statement *statement) {
+//   golibrdf_parse_target *target = (golibrdf_parse_target*)user_data;
+//   if(librdf_model_add_statement(target->model, statement))
+//     target->failed++;
+// }
+//
+// static void golibrdf_record_namespace(void *user_data, raptor_namespace *nspace) {
+//   golibrdf_parse_target *target = (golibrdf_parse_target*)user_data;
+//   raptor_uri *uri = raptor_namespace_get_uri(nspace);
+//   if(uri)
+//     golibrdfParserNamespace(target->parser, (char*)raptor_namespace_get_prefix(nspace), (char*)raptor_uri_as_string(uri));
+// }
+//
+// static void golibrdf_set_parse_handlers(raptor_parser *parser, golibrdf_parse_target *target) {
+//   raptor_parser_set_statement_handler(parser, target, golibrdf_add_statement_to_model);
+//   raptor_parser_set_namespace_handler(parser, target, golibrdf_record_namespace);
+// }
 import "C"
 
 import (
-	"errors"
+	"bytes"
 	"fmt"
+	"io"
+	"iter"
 	"runtime"
+	"runtime/cgo"
 	"unsafe"
 )
 
+//size of the chunks read from an io.Reader and passed to the parser
+const parseChunkSize = 64 * 1024
+
 //Parser used to read and transform data in various formats into a model
 type Parser struct {
-	librdf_parser *C.librdf_parser
-	Name          string
-	world         *World
-	mimeType      string
+	librdf_parser    *C.librdf_parser
+	Name             string
+	world            *World
+	mimeType         string
+	readerNamespaces []Namespace
 }
 
 //NewParser constructs a new parser given a parserName and mimeType
 //mimeType may be left empty
 func NewParser(world *World, parserName string, mimeType string) (*Parser, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &ParserError{Op: "create parser", Syntax: parserName, Err: err}
+	}
 
 	parser := Parser{Name: parserName, mimeType: mimeType}
 	parser.world = world
@@ -58,16 +95,58 @@ func NewParser(world *World, parserName string, mimeType string) (*Parser, error
 	defer C.free(unsafe.Pointer(cMimeType))
 
 	parser.librdf_parser = C.librdf_new_parser(world.librdf_world, cParserName, cMimeType, nil)
+	if parser.librdf_parser == nil {
+		return nil, &ParserError{Op: "create parser", Syntax: parser.syntax(), Err: ErrUnsupportedSyntax}
+	}
+
 	runtime.SetFinalizer(&parser, (*Parser).Free)
 
 	return &parser, nil
 }
 
+//NewParserForContent constructs a new parser for the syntax guessed from the mime type, content and URI of RDF data
+//	Any of mimeType, content and uri may be empty or nil.  Refer to World.GuessParser for how the syntax is guessed.
+func NewParserForContent(world *World, mimeType string, content []byte, uri *Uri) (*Parser, error) {
+	parserName, err := world.GuessParser(mimeType, content, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewParser(world, parserName, mimeType)
+}
+
+//NewParserForReader constructs a new parser for the syntax guessed from the mime type, URI and the first chunk of data read from r
+//	The returned reader yields all of the data from r, including the chunk read to guess the syntax, so it
+//	should be passed to ParseReaderIntoModel in place of r
+func NewParserForReader(world *World, mimeType string, r io.Reader, uri *Uri) (*Parser, io.Reader, error) {
+	if r == nil {
+		return nil, nil, &ParserError{Op: "guess parser", Syntax: mimeType, Err: ErrNilArgument}
+	}
+
+	content := make([]byte, parseChunkSize)
+	n, err := io.ReadFull(r, content)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, nil, err
+	}
+	content = content[:n]
+
+	parser, err := NewParserForContent(world, mimeType, content, uri)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parser, io.MultiReader(bytes.NewReader(content), r), nil
+}
+
 //Parse a string containing RDF dat into a model
 func (parser *Parser) ParseStringIntoModel(rdfString string, baseUri *Uri, model *Model) error {
 
 	var err error
 
+	if err = parser.validate("parse string", checkModel(model)); err != nil {
+		return err
+	}
+
 	var baseUriPtr *C.librdf_uri
 	baseUriPtr = nil
 
@@ -78,10 +157,12 @@ func (parser *Parser) ParseStringIntoModel(rdfString string, baseUri *Uri, model
 		baseUriPtr = baseUri.librdf_uri
 	}
 
+	collector := parser.world.startLogCollector()
 	result := C.librdf_parser_parse_string_into_model(parser.librdf_parser, (*C.uchar)(unsafe.Pointer(cRdfString)), baseUriPtr, model.librdf_model)
+	messages := parser.world.stopLogCollector(collector)
 
-	if result != 0 {
-		err = errors.New("Unable to parse string into model")
+	if result != 0 || hasErrors(messages) {
+		err = newParseError("Unable to parse string into model", messages)
 	}
 
 	return err
@@ -92,6 +173,10 @@ func (parser *Parser) ParseIntoModel(uri *Uri, baseUri *Uri, model *Model) error
 
 	var err error
 
+	if err = parser.validate("parse URI", checkUri(uri), checkModel(model)); err != nil {
+		return err
+	}
+
 	var baseUriPtr *C.librdf_uri
 	baseUriPtr = nil
 
@@ -99,24 +184,305 @@ func (parser *Parser) ParseIntoModel(uri *Uri, baseUri *Uri, model *Model) error
 		baseUriPtr = baseUri.librdf_uri
 	}
 
+	collector := parser.world.startLogCollector()
 	result := C.librdf_parser_parse_into_model(parser.librdf_parser, uri.librdf_uri, baseUriPtr, model.librdf_model)
+	messages := parser.world.stopLogCollector(collector)
 
-	fmt.Printf("%s", "parsed")
-
-	if result != 0 {
-		err = errors.New("Unable to parse URI into model")
+	if result != 0 || hasErrors(messages) {
+		err = newParseFailure("Unable to parse URI into model", uri.ToString(), messages)
 	}
 
 	return err
 }
 
+//ParseReaderIntoModel parses RDF data read from r into a model
+//	The data is passed to the parser in chunks as it is read, so memory use does not grow with the size of the input.
+//	baseUri may be nil for syntaxes that do not need a base URI, such as N-Triples
+func (parser *Parser) ParseReaderIntoModel(r io.Reader, baseUri *Uri, model *Model) error {
+	if err := parser.validate("parse reader", checkModel(model)); err != nil {
+		return err
+	}
+
+	if r == nil {
+		return &ParserError{Op: "parse reader", Syntax: parser.syntax(), Err: ErrNilArgument}
+	}
+
+	raptorParser, err := parser.newRaptorParser()
+	if err != nil {
+		return err
+	}
+	defer C.raptor_free_parser(raptorParser)
+
+	// the target is allocated in C memory as raptor holds on to it between calls
+	target := (*C.golibrdf_parse_target)(C.calloc(1, C.size_t(unsafe.Sizeof(C.golibrdf_parse_target{}))))
+	if target == nil {
+		return &ParserError{Op: "allocate parser state", Syntax: parser.syntax(), Err: ErrNullResult}
+	}
+	defer C.free(unsafe.Pointer(target))
+
+	handle := cgo.NewHandle(parser)
+	defer handle.Delete()
+
+	target.model = model.librdf_model
+	target.parser = C.uintptr_t(handle)
+	C.golibrdf_set_parse_handlers(raptorParser, target)
+
+	collector := parser.world.startLogCollector()
+	parseFailure, err := parseReaderChunks(raptorParser, r, baseUri)
+	messages := parser.world.stopLogCollector(collector)
+
+	if err != nil {
+		return err
+	}
+
+	if parseFailure != "" || hasErrors(messages) {
+		if parseFailure == "" {
+			parseFailure = "Unable to parse data read from reader"
+		}
+		return newParseError(parseFailure, messages)
+	}
+
+	if target.failed != 0 {
+		return &ModelError{Op: fmt.Sprintf("add %d parsed statements", int(target.failed)), Err: ErrLibrdfFailed}
+	}
+
+	return nil
+}
+
+//ParseAsStream returns an iterator over the statements parsed from the data at a specified URI, without adding them to a model
+//	Statements are parsed as the iterator is read and the librdf stream is freed when the loop ends or breaks.
+//	The receiver owns each statement returned.
+func (parser *Parser) ParseAsStream(uri *Uri, baseUri *Uri) iter.Seq2[*Statement, error] {
+	if err := parser.validate("parse URI as stream", checkUri(uri)); err != nil {
+		return errorSeq[*Statement](err)
+	}
+
+	return parser.withParseErrors("Unable to parse URI as stream", uri.ToString(), statementStreamSeq(parser.world, func() *C.librdf_stream {
+		var baseUriPtr *C.librdf_uri
+		if baseUri != nil {
+			baseUriPtr = baseUri.librdf_uri
+		}
+
+		return C.librdf_parser_parse_as_stream(parser.librdf_parser, uri.librdf_uri, baseUriPtr)
+	}))
+}
+
+//ParseStringAsStream returns an iterator over the statements parsed from a string containing RDF data, without adding them to a model
+//	Statements are parsed as the iterator is read and the librdf stream is freed when the loop ends or breaks.
+//	The receiver owns each statement returned.
+func (parser *Parser) ParseStringAsStream(rdfString string, baseUri *Uri) iter.Seq2[*Statement, error] {
+	if err := parser.validate("parse string as stream"); err != nil {
+		return errorSeq[*Statement](err)
+	}
+
+	return func(yield func(*Statement, error) bool) {
+		// librdf parses the string as the stream is read, so it must outlive the stream
+		cRdfString := C.CString(rdfString)
+		defer C.free(unsafe.Pointer(cRdfString))
+
+		statements := statementStreamSeq(parser.world, func() *C.librdf_stream {
+			var baseUriPtr *C.librdf_uri
+			if baseUri != nil {
+				baseUriPtr = baseUri.librdf_uri
+			}
+
+			return C.librdf_parser_parse_counted_string_as_stream(parser.librdf_parser, (*C.uchar)(unsafe.Pointer(cRdfString)), C.size_t(len(rdfString)), baseUriPtr)
+		})
+
+		for statement, err := range parser.withParseErrors("Unable to parse string as stream", "", statements) {
+			if !yield(statement, err) {
+				return
+			}
+		}
+	}
+}
+
+//withParseErrors gathers the messages logged while statements are parsed, yielding an error once
+//	parsing ends if any errors were logged.  Iteration stops at the first error.
+//	uri names the data being parsed from a URI, so that failures to retrieve it are reported as a *FetchError,
+//	and is empty when parsing a string.  Messages are not gathered while the receiver's loop body runs.
+func (parser *Parser) withParseErrors(summary string, uri string, statements iter.Seq2[*Statement, error]) iter.Seq2[*Statement, error] {
+	parseFailure := func(messages []LogMessage) error {
+		if uri == "" {
+			return newParseError(summary, messages)
+		}
+		return newParseFailure(summary, uri, messages)
+	}
+
+	return func(yield func(*Statement, error) bool) {
+		var messages []LogMessage
+
+		collector := parser.world.startLogCollector()
+		isCollecting := true
+
+		stopCollecting := func() {
+			if isCollecting {
+				messages = append(messages, parser.world.stopLogCollector(collector)...)
+				isCollecting = false
+			}
+		}
+		defer stopCollecting()
+
+		for statement, err := range statements {
+			stopCollecting()
+
+			if err != nil {
+				yield(nil, parseFailure(messages))
+				return
+			}
+
+			if !yield(statement, nil) {
+				return
+			}
+
+			collector = parser.world.startLogCollector()
+			isCollecting = true
+		}
+
+		if stopCollecting(); hasErrors(messages) {
+			yield(nil, parseFailure(messages))
+		}
+	}
+}
+
+//NamespacesSeen returns the namespaces declared in the data parsed so far
+//	Namespaces are recorded for all parsing methods and accumulate across calls
+func (parser *Parser) NamespacesSeen() []Namespace {
+	var namespaces []Namespace
+
+	if parser.validate("read namespaces") != nil {
+		return namespaces
+	}
+
+	count := int(C.librdf_parser_get_namespaces_seen_count(parser.librdf_parser))
+	for i := 0; i < count; i++ {
+		librdf_uri := C.librdf_parser_get_namespaces_seen_uri(parser.librdf_parser, C.int(i))
+		if librdf_uri == nil {
+			continue
+		}
+
+		uriString := C.GoString((*C.char)(unsafe.Pointer(C.librdf_uri_as_string(librdf_uri))))
+		namespaces = appendNamespace(namespaces, Namespace{Prefix: C.GoString(C.librdf_parser_get_namespaces_seen_prefix(parser.librdf_parser, C.int(i))), Uri: uriString})
+	}
+
+	for _, namespace := range parser.readerNamespaces {
+		namespaces = appendNamespace(namespaces, namespace)
+	}
+
+	return namespaces
+}
+
+//appendNamespace adds a namespace to a list, replacing any namespace with the same prefix
+func appendNamespace(namespaces []Namespace, namespace Namespace) []Namespace {
+	for i := range namespaces {
+		if namespaces[i].Prefix == namespace.Prefix {
+			namespaces[i] = namespace
+			return namespaces
+		}
+	}
+
+	return append(namespaces, namespace)
+}
+
+//newRaptorParser constructs a raptor parser for the syntax of the parser
+//	"raptor" is the librdf name for the RDF/XML parser; when no name is given one is chosen from the mime type
+func (parser *Parser) newRaptorParser() (*C.raptor_parser, error) {
+	raptorWorld := parser.world.GetRaptorWorld()
+	if raptorWorld == nil {
+		return nil, &ParserError{Op: "create parser", Syntax: parser.syntax(), Err: ErrWorldClosed}
+	}
+
+	var cParserName *C.char
+	switch parser.Name {
+	case "raptor":
+		cParserName = C.CString("rdfxml")
+		defer C.free(unsafe.Pointer(cParserName))
+	case "":
+		cMimeType := C.CString(parser.mimeType)
+		defer C.free(unsafe.Pointer(cMimeType))
+
+		// raptor owns the returned name, which must not be freed
+		cParserName = C.raptor_world_guess_parser_name(raptorWorld, nil, cMimeType, nil, 0, nil)
+	default:
+		cParserName = C.CString(parser.Name)
+		defer C.free(unsafe.Pointer(cParserName))
+	}
+
+	raptorParser := C.raptor_new_parser(raptorWorld, cParserName)
+	if raptorParser == nil {
+		return nil, &ParserError{Op: "create parser", Syntax: parser.syntax(), Err: ErrUnsupportedSyntax}
+	}
+
+	return raptorParser, nil
+}
+
+//parseReaderChunks passes the data read from r to a raptor parser in chunks
+//	A description of the failure is returned if the parser fails, errors reading from r are returned as they are
+func parseReaderChunks(raptorParser *C.raptor_parser, r io.Reader, baseUri *Uri) (string, error) {
+	var baseUriPtr *C.librdf_uri
+	if baseUri != nil {
+		baseUriPtr = baseUri.librdf_uri
+	}
+
+	if retCode := C.raptor_parser_parse_start(raptorParser, baseUriPtr); retCode != 0 {
+		return "Unable to start parsing", nil
+	}
+
+	buffer := make([]byte, parseChunkSize)
+	for {
+		n, readErr := r.Read(buffer)
+
+		if n > 0 {
+			if retCode := C.raptor_parser_parse_chunk(raptorParser, (*C.uchar)(unsafe.Pointer(&buffer[0])), C.size_t(n), 0); retCode != 0 {
+				return "Unable to parse data read from reader", nil
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return "", readErr
+		}
+	}
+
+	if retCode := C.raptor_parser_parse_chunk(raptorParser, nil, 0, 1); retCode != 0 {
+		return "Unable to parse data read from reader", nil
+	}
+
+	return "", nil
+}
+
 //Free cleans up memory resources held by the Parser
 //	Free will be automatically called when Parser instances are garbage collected
 //  however it is important to explicitly call Free to avoid issues that may result
 //  from freeing resources in an unexpected order
 func (parser *Parser) Free() {
-	if parser.librdf_parser != nil {
+	if parser != nil && parser.librdf_parser != nil {
 		C.librdf_free_parser(parser.librdf_parser)
 		parser.librdf_parser = nil
 	}
 }
+
+//validate returns a *ParserError for op if the parser has been freed or any of the argument checks failed
+func (parser *Parser) validate(op string, argumentErrors ...error) error {
+	if parser == nil || parser.librdf_parser == nil {
+		return &ParserError{Op: op, Err: ErrFreed}
+	}
+
+	for _, err := range argumentErrors {
+		if err != nil {
+			return &ParserError{Op: op, Syntax: parser.syntax(), Err: err}
+		}
+	}
+
+	return nil
+}
+
+//syntax returns the name of the parser, or its mime type if no name was given
+func (parser *Parser) syntax() string {
+	if parser.Name == "" {
+		return parser.mimeType
+	}
+	return parser.Name
+}
@@ -29,17 +29,271 @@ package golibrdf
 // #include <librdf.h>
 import "C"
 
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
 //A Query that can be executed against a model to produce results 
+//	The query is prepared when it is constructed and may be executed any number of times.
+//	Copies of a Query share the same prepared librdf query.
 type Query struct {
 	world       *World
 	name        string
 	queryString string
+	handle      *queryHandle
+}
+
+//queryHandle holds the prepared librdf query shared by copies of a Query
+//	librdf allows only one set of results per query, so the handle records whether results from an execution
+//	are still live.  The handle does not refer to the results, which instead refer to the handle in order to
+//	keep the librdf query alive until they have been freed.  If the query is executed again while results are
+//	live, those results keep the librdf query they were read from and the handle prepares a new one.
+type queryHandle struct {
+	mutex           sync.Mutex
+	world           *World
+	name            string
+	queryString     string
+	librdf_base_uri *C.librdf_uri
+	librdf_query    *C.librdf_query
+	isExecuting     bool
+	isFreed         bool
 }
 
 //NewQuery constructs a new query given a name indicating the query type and a string containing the query 
 func NewQuery(world *World, name string, queryString string) (Query, error) {
+	return NewQueryWithBaseUri(world, name, queryString, nil)
+}
 
-	var err error = nil
+//NewQueryWithBaseUri constructs a new query given a name indicating the query type, a string containing the query
+//	and a base URI used to resolve relative URIs in the query.  baseUri may be nil.
+func NewQueryWithBaseUri(world *World, name string, queryString string, baseUri *Uri) (Query, error) {
 	query := Query{world: world, name: name, queryString: queryString}
-	return query, err
+
+	if err := checkWorld(world); err != nil {
+		return query, &QueryError{Op: "create query", Language: name, Err: err}
+	}
+
+	handle := &queryHandle{world: world, name: name, queryString: queryString}
+	if baseUri != nil && baseUri.librdf_uri != nil {
+		// the base URI is copied so that the query can be prepared again after the caller frees baseUri
+		handle.librdf_base_uri = C.librdf_new_uri_from_uri(baseUri.librdf_uri)
+	}
+
+	if err := handle.prepare(); err != nil {
+		handle.free()
+		return query, &QueryError{Op: "create query", Language: name, Err: err}
+	}
+
+	query.handle = handle
+	runtime.SetFinalizer(query.handle, (*queryHandle).free)
+
+	return query, nil
+}
+
+//GetLimit returns the maximum number of results the query will return, or a negative number if there is no limit
+func (query *Query) GetLimit() int {
+	limit := -1
+	query.handle.use(func(librdf_query *C.librdf_query) {
+		limit = int(C.librdf_query_get_limit(librdf_query))
+	})
+	return limit
+}
+
+//SetLimit sets the maximum number of results the query will return
+//	A negative limit removes any limit.  The limit applies to subsequent executions of the query.
+func (query *Query) SetLimit(limit int) error {
+	var retCode C.int
+	if !query.handle.use(func(librdf_query *C.librdf_query) {
+		retCode = C.librdf_query_set_limit(librdf_query, C.int(limit))
+	}) {
+		return &QueryError{Op: "set limit", Language: query.name, Err: ErrFreed}
+	}
+
+	if retCode != 0 {
+		return &QueryError{Op: "set limit", Language: query.name, Err: ErrLibrdfFailed}
+	}
+	return nil
+}
+
+//GetOffset returns the number of results the query will skip, or a negative number if no offset is set
+func (query *Query) GetOffset() int {
+	offset := -1
+	query.handle.use(func(librdf_query *C.librdf_query) {
+		offset = int(C.librdf_query_get_offset(librdf_query))
+	})
+	return offset
+}
+
+//SetOffset sets the number of results the query will skip before returning results
+//	A negative offset removes any offset.  The offset applies to subsequent executions of the query.
+func (query *Query) SetOffset(offset int) error {
+	var retCode C.int
+	if !query.handle.use(func(librdf_query *C.librdf_query) {
+		retCode = C.librdf_query_set_offset(librdf_query, C.int(offset))
+	}) {
+		return &QueryError{Op: "set offset", Language: query.name, Err: ErrFreed}
+	}
+
+	if retCode != 0 {
+		return &QueryError{Op: "set offset", Language: query.name, Err: ErrLibrdfFailed}
+	}
+	return nil
+}
+
+//Free cleans up the prepared librdf query
+//	If results from an execution of the query have not yet been freed, the librdf query is freed along with them.
+//	Free will be automatically called when the query is garbage collected, however it is important
+//	to explicitly call Free to avoid issues that may result from freeing resources in an unexpected order
+func (query *Query) Free() {
+	if query.handle != nil {
+		query.handle.free()
+	}
+}
+
+//execute executes the prepared query against a model
+//	librdf does not allow results to outlive a new execution of the same query, so if results from a previous
+//	execution have not been freed the query is prepared again and the previous results keep the old librdf query
+func (query *Query) execute(model *Model) (*QueryResults, error) {
+	if query == nil || query.handle == nil {
+		return nil, &QueryError{Op: "execute query", Err: ErrFreed}
+	}
+
+	if err := checkModel(model); err != nil {
+		return nil, &QueryError{Op: "execute query", Language: query.name, Err: err}
+	}
+
+	handle := query.handle
+	handle.mutex.Lock()
+	defer handle.mutex.Unlock()
+
+	if handle.isFreed || handle.librdf_query == nil {
+		return nil, &QueryError{Op: "execute query", Language: query.name, Err: ErrFreed}
+	}
+
+	if handle.isExecuting {
+		if err := handle.prepareAgain(); err != nil {
+			return nil, &QueryError{Op: "execute query", Language: query.name, Err: err}
+		}
+	}
+
+	collector := query.world.startLogCollector()
+	librdf_query_results := C.librdf_query_execute(handle.librdf_query, model.librdf_model)
+	messages := query.world.stopLogCollector(collector)
+
+	if librdf_query_results == nil {
+		return nil, &QueryError{Op: "execute query", Language: query.name, Err: logMessagesError(ErrLibrdfFailed, messages)}
+	}
+	handle.isExecuting = true
+
+	results := QueryResults{world: query.world, handle: handle, librdf_query: handle.librdf_query, librdf_query_results: librdf_query_results}
+	runtime.SetFinalizer(&results, (*QueryResults).Free)
+
+	return &results, nil
+}
+
+//prepare prepares the librdf query from the handle's query text and base URI
+//	A *ParseError is returned if librdf reports errors in the query text
+func (handle *queryHandle) prepare() error {
+	if err := checkWorld(handle.world); err != nil {
+		return err
+	}
+
+	cQueryString := C.CString(handle.queryString)
+	defer C.free(unsafe.Pointer(cQueryString))
+
+	cName := C.CString(handle.name)
+	defer C.free(unsafe.Pointer(cName))
+
+	collector := handle.world.startLogCollector()
+	librdf_query := C.librdf_new_query(handle.world.librdf_world, (*C.char)(unsafe.Pointer(cName)), nil, (*C.uchar)(unsafe.Pointer(cQueryString)), handle.librdf_base_uri)
+	messages := handle.world.stopLogCollector(collector)
+
+	if librdf_query == nil {
+		if hasErrors(messages) {
+			return newParseError("Invalid query", messages)
+		}
+		return ErrNullResult
+	}
+
+	handle.librdf_query = librdf_query
+	return nil
+}
+
+//prepareAgain replaces a librdf query that has live results with a newly prepared one having the same limit and offset
+//	The live results take ownership of the old librdf query and free it along with themselves.
+//	The caller must hold the handle's mutex
+func (handle *queryHandle) prepareAgain() error {
+	previous := handle.librdf_query
+
+	if err := handle.prepare(); err != nil {
+		return err
+	}
+
+	C.librdf_query_set_limit(handle.librdf_query, C.librdf_query_get_limit(previous))
+	C.librdf_query_set_offset(handle.librdf_query, C.librdf_query_get_offset(previous))
+	handle.isExecuting = false
+
+	return nil
+}
+
+//use calls fn with the prepared librdf query, returning false without calling fn if the query has been freed
+func (handle *queryHandle) use(fn func(librdf_query *C.librdf_query)) bool {
+	if handle == nil {
+		return false
+	}
+
+	handle.mutex.Lock()
+	defer handle.mutex.Unlock()
+
+	if handle.isFreed || handle.librdf_query == nil {
+		return false
+	}
+
+	fn(handle.librdf_query)
+	return true
+}
+
+//free marks the handle as freed, freeing the prepared librdf query unless results from an execution are still live
+func (handle *queryHandle) free() {
+	handle.mutex.Lock()
+	defer handle.mutex.Unlock()
+
+	handle.isFreed = true
+	handle.freeIfUnused()
+}
+
+//resultsFreed records that results read from librdf_query have been freed
+//	Results of the current execution free the prepared librdf query if Free has already been called, while
+//	results of an execution that has since been superseded free the librdf query they own
+func (handle *queryHandle) resultsFreed(librdf_query *C.librdf_query) {
+	handle.mutex.Lock()
+	defer handle.mutex.Unlock()
+
+	if librdf_query != handle.librdf_query {
+		C.librdf_free_query(librdf_query)
+		return
+	}
+
+	handle.isExecuting = false
+	handle.freeIfUnused()
+}
+
+//freeIfUnused frees the prepared librdf query and base URI once the handle has been freed and has no live results
+//	The caller must hold the handle's mutex
+func (handle *queryHandle) freeIfUnused() {
+	if !handle.isFreed || handle.isExecuting {
+		return
+	}
+
+	if handle.librdf_query != nil {
+		C.librdf_free_query(handle.librdf_query)
+		handle.librdf_query = nil
+	}
+
+	if handle.librdf_base_uri != nil {
+		C.librdf_free_uri(handle.librdf_base_uri)
+		handle.librdf_base_uri = nil
+	}
 }
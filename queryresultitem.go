@@ -21,7 +21,82 @@
 
 package golibrdf
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
 //QueryResultItem represents a row of query results containing a list of nodes (with their bound names and values)
 type QueryResultItem struct {
 	NameNodePairs []NameNodePair
+	index         map[string]int
+}
+
+//Get returns the node bound to the named variable, or nil if the variable is not bound in the row
+//	name may be given with or without its ? or $ prefix
+func (item *QueryResultItem) Get(name string) *Node {
+	name = strings.TrimLeft(name, "?$")
+
+	if item.index == nil {
+		for _, nameNodePair := range item.NameNodePairs {
+			if nameNodePair.Name == name {
+				return boundNodeOrNil(nameNodePair.Node)
+			}
+		}
+		return nil
+	}
+
+	if i, ok := item.index[name]; ok {
+		return boundNodeOrNil(item.NameNodePairs[i].Node)
+	}
+	return nil
+}
+
+//Decode fills the fields of the struct pointed to by v from the row
+//	Fields are matched to variables using tags of the form `rdf:"?name"`, untagged fields and fields tagged
+//	`rdf:"-"` are left unchanged.  Fields for variables that are not bound in the row are set to their zero value.
+//	Literals are converted as for Node.Value and then to the field type, resources may be decoded to string or
+//	*url.URL fields and any node may be decoded to a *Node field, which receives a copy the caller owns.
+func (item *QueryResultItem) Decode(v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return errors.New("Unable to decode query result.  A non-nil pointer to a struct is required.")
+	}
+
+	structValue := target.Elem()
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		tag := field.Tag.Get("rdf")
+		if tag == "" || tag == "-" || field.PkgPath != "" {
+			continue
+		}
+
+		if err := assignNode(structValue.Field(i), item.Get(tag)); err != nil {
+			return fmt.Errorf("Unable to decode variable %s into field %s: %w", tag, field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+//boundNodeOrNil returns nil for a node that does not hold a term
+func boundNodeOrNil(node *Node) *Node {
+	if node.isUnbound() {
+		return nil
+	}
+	return node
+}
+
+//free cleans up the nodes held by a QueryResultItem that will not be passed to a receiver
+func (item *QueryResultItem) free() {
+	for _, nameNodePair := range item.NameNodePairs {
+		if nameNodePair.Node != nil {
+			nameNodePair.Node.Free()
+		}
+	}
 }
@@ -0,0 +1,274 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information
+* on the Redland libraries that this package wraps
+*
+* This golibrdf package is:
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+*
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+ */
+
+package golibrdf
+
+// #cgo linux pkg-config: redland raptor2
+// #cgo LDFLAGS: -lrdf
+// #include <stdlib.h>
+// #include <string.h>
+// #include <strings.h>
+// #include <librdf.h>
+import "C"
+
+import (
+	"iter"
+	"sync"
+)
+
+//QueryResults holds the results of executing a query against a model
+//	Free should be called once the results are no longer required.  Executing the same Query again before the
+//	results are freed prepares the query again, so the results remain readable.  Free may be called from any
+//	goroutine, including while the results are being read, in which case the librdf results are freed once reading stops.
+type QueryResults struct {
+	world                *World
+	handle               *queryHandle
+	librdf_query         *C.librdf_query
+	mutex                sync.Mutex
+	librdf_query_results *C.librdf_query_results
+	readers              int
+	isFreed              bool
+}
+
+//ExecuteQuery executes a query against the model and returns the results
+//	The results should be freed once they are no longer required.  Results that are not freed hold the librdf
+//	query they were read from until they are garbage collected, as each later execution prepares a new one.
+func (model *Model) ExecuteQuery(query *Query) (*QueryResults, error) {
+	return query.execute(model)
+}
+
+//IsBindings returns true if the results are variable bindings, as returned by a SELECT query
+func (results *QueryResults) IsBindings() bool {
+	return results.test(func(librdf_query_results *C.librdf_query_results) bool {
+		return C.librdf_query_results_is_bindings(librdf_query_results) != 0
+	})
+}
+
+//IsBoolean returns true if the results are a boolean, as returned by an ASK query
+func (results *QueryResults) IsBoolean() bool {
+	return results.test(func(librdf_query_results *C.librdf_query_results) bool {
+		return C.librdf_query_results_is_boolean(librdf_query_results) != 0
+	})
+}
+
+//IsGraph returns true if the results are an RDF graph, as returned by a CONSTRUCT or DESCRIBE query
+func (results *QueryResults) IsGraph() bool {
+	return results.test(func(librdf_query_results *C.librdf_query_results) bool {
+		return C.librdf_query_results_is_graph(librdf_query_results) != 0
+	})
+}
+
+//Boolean returns the value of boolean results
+func (results *QueryResults) Boolean() (bool, error) {
+	if !results.IsBoolean() {
+		return false, &QueryError{Op: "read boolean result", Err: ErrResultForm}
+	}
+
+	librdf_query_results, err := results.acquire("read boolean result")
+	if err != nil {
+		return false, err
+	}
+	defer results.release()
+
+	value := C.librdf_query_results_get_boolean(librdf_query_results)
+	if value < 0 {
+		return false, &QueryError{Op: "read boolean result", Err: ErrLibrdfFailed}
+	}
+
+	return value > 0, nil
+}
+
+//BindingNames returns the names of the variables bound in variable binding results
+func (results *QueryResults) BindingNames() ([]string, error) {
+	if !results.IsBindings() {
+		return nil, &QueryError{Op: "read binding names", Err: ErrResultForm}
+	}
+
+	librdf_query_results, err := results.acquire("read binding names")
+	if err != nil {
+		return nil, err
+	}
+	defer results.release()
+
+	bindingCount := int(C.librdf_query_results_get_bindings_count(librdf_query_results))
+	names := make([]string, bindingCount)
+
+	for i := 0; i < bindingCount; i++ {
+		names[i] = C.GoString(C.librdf_query_results_get_binding_name(librdf_query_results, C.int(i)))
+	}
+
+	return names, nil
+}
+
+//Statements returns an iterator over the statements of graph results
+//	Statements are read on the caller's goroutine and the librdf stream is freed when the loop ends or breaks.
+//	The receiver owns each statement returned.
+func (results *QueryResults) Statements() iter.Seq2[*Statement, error] {
+	if !results.IsGraph() {
+		return errorSeq[*Statement](&QueryError{Op: "read statements", Err: ErrResultForm})
+	}
+
+	return func(yield func(*Statement, error) bool) {
+		librdf_query_results, err := results.acquire("read statements")
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer results.release()
+
+		statements := statementStreamSeq(results.world, func() *C.librdf_stream {
+			return C.librdf_query_results_as_stream(librdf_query_results)
+		})
+
+		for statement, err := range statements {
+			if !yield(statement, err) {
+				return
+			}
+		}
+	}
+}
+
+//Rows returns an iterator over the rows of a set of variable binding results
+//	Rows are read on the caller's goroutine.  The underlying librdf results can only be read once, so
+//	ranging over Rows a second time continues from where the previous loop stopped.
+func (results *QueryResults) Rows() iter.Seq2[*QueryResultItem, error] {
+	return func(yield func(*QueryResultItem, error) bool) {
+		if !results.IsBindings() {
+			yield(nil, &QueryError{Op: "read rows", Err: ErrResultForm})
+			return
+		}
+
+		librdf_query_results, err := results.acquire("read rows")
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer results.release()
+
+		for C.librdf_query_results_finished(librdf_query_results) == 0 {
+			if results.isFreeRequested() {
+				yield(nil, &QueryError{Op: "read rows", Err: ErrFreed})
+				return
+			}
+
+			item := newQueryResultItem(results.world, librdf_query_results)
+
+			// advance before yielding so that a loop that breaks does not see the same row again
+			C.librdf_query_results_next(librdf_query_results)
+
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+//Free cleans up the results
+//	If the results are being read the librdf results are freed once reading stops
+func (results *QueryResults) Free() {
+	results.mutex.Lock()
+	defer results.mutex.Unlock()
+
+	results.isFreed = true
+	results.freeIfUnread()
+}
+
+//test returns the result of fn for the librdf results, or false if the results have been freed
+func (results *QueryResults) test(fn func(librdf_query_results *C.librdf_query_results) bool) bool {
+	librdf_query_results, err := results.acquire("")
+	if err != nil {
+		return false
+	}
+	defer results.release()
+
+	return fn(librdf_query_results)
+}
+
+//acquire returns the librdf results and registers a reader, so that they are not freed until release is called
+//	A *QueryError for op wrapping ErrFreed is returned if Free has been called
+func (results *QueryResults) acquire(op string) (*C.librdf_query_results, error) {
+	results.mutex.Lock()
+	defer results.mutex.Unlock()
+
+	if results.isFreed || results.librdf_query_results == nil {
+		return nil, &QueryError{Op: op, Err: ErrFreed}
+	}
+
+	results.readers++
+	return results.librdf_query_results, nil
+}
+
+//release unregisters a reader registered by acquire, freeing the librdf results if Free was called while reading
+func (results *QueryResults) release() {
+	results.mutex.Lock()
+	defer results.mutex.Unlock()
+
+	results.readers--
+	results.freeIfUnread()
+}
+
+//isFreeRequested returns true once Free has been called
+func (results *QueryResults) isFreeRequested() bool {
+	results.mutex.Lock()
+	defer results.mutex.Unlock()
+
+	return results.isFreed
+}
+
+//freeIfUnread frees the librdf results once Free has been called and no reader remains, and then
+//	lets the query that produced them be executed again
+//	The caller must hold the results' mutex
+func (results *QueryResults) freeIfUnread() {
+	if !results.isFreed || results.readers > 0 || results.librdf_query_results == nil {
+		return
+	}
+
+	C.librdf_free_query_results(results.librdf_query_results)
+	results.librdf_query_results = nil
+
+	if results.handle != nil {
+		results.handle.resultsFreed(results.librdf_query)
+	}
+}
+
+//newQueryResultItem builds a QueryResultItem from the current row of a set of query results
+func newQueryResultItem(world *World, results *C.librdf_query_results) *QueryResultItem {
+	item := new(QueryResultItem)
+
+	bindingCount := int(C.librdf_query_results_get_bindings_count(results))
+
+	item.NameNodePairs = make([]NameNodePair, bindingCount, bindingCount)
+	item.index = make(map[string]int, bindingCount)
+
+	for i := 0; i < bindingCount; i++ {
+		cName := C.librdf_query_results_get_binding_name(results, C.int(i))
+		librdf_node := C.librdf_query_results_get_binding_value(results, C.int(i))
+
+		item.NameNodePairs[i].Name = C.GoString(cName)
+		node := new(Node)
+		node.world = world
+		node.librdf_node = librdf_node
+		item.NameNodePairs[i].Node = node
+		item.index[item.NameNodePairs[i].Name] = i
+	}
+
+	return item
+}
@@ -26,33 +26,74 @@ package golibrdf
 // #include <stdlib.h>
 // #include <string.h>
 // #include <strings.h>
+// #include <stdint.h>
 // #include <librdf.h>
+//
+// extern int golibrdfIostreamWriteBytes(uintptr_t handle, void *ptr, size_t size, size_t nmemb);
+//
+// static int golibrdf_iostream_write_byte(void *context, const int byte) {
+//   unsigned char c = (unsigned char)byte;
+//   return golibrdfIostreamWriteBytes((uintptr_t)context, &c, 1, 1) == 1 ? 0 : 1;
+// }
+//
+// static int golibrdf_iostream_write_bytes(void *context, const void *ptr, size_t size, size_t nmemb) {
+//   return golibrdfIostreamWriteBytes((uintptr_t)context, (void*)ptr, size, nmemb);
+// }
+//
+// static const raptor_iostream_handler golibrdf_writer_iostream_handler = {
+//   2, NULL, NULL, golibrdf_iostream_write_byte, golibrdf_iostream_write_bytes, NULL, NULL, NULL
+// };
+//
+// static raptor_iostream* golibrdf_new_writer_iostream(raptor_world *world, uintptr_t handle) {
+//   return raptor_new_iostream_from_handler(world, (void*)handle, &golibrdf_writer_iostream_handler);
+// }
 import "C"
 
 import (
-	"errors"
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"os"
 	"runtime"
+	"runtime/cgo"
 	"unsafe"
 )
 
 //A Serializer used to serialize a model into various formats
 type Serializer struct {
 	librdf_serializer *C.librdf_serializer
+	world             *World
+	name              string
+	mimeType          string
+	namespaces        []Namespace
 }
 
 //NewSerializer construcs a new serializer based on a name defining the type, a mimeType and optional URI
 func NewSerializer(world *World, name string, mimeType string, uri *Uri) (*Serializer, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &SerializerError{Op: "create serializer", Syntax: name, Err: err}
+	}
 
-	serializer := Serializer{}
+	serializer := Serializer{world: world, name: name, mimeType: mimeType}
 
 	var uriPtr *C.librdf_uri
 	uriPtr = nil
 
-	if uriPtr != nil {
+	if uri != nil {
 		uriPtr = uri.librdf_uri
 	}
 
-	serializer.librdf_serializer = C.librdf_new_serializer(world.librdf_world, C.CString(name), C.CString(mimeType), uriPtr)
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	cMimeType := C.CString(mimeType)
+	defer C.free(unsafe.Pointer(cMimeType))
+
+	serializer.librdf_serializer = C.librdf_new_serializer(world.librdf_world, cName, cMimeType, uriPtr)
+	if serializer.librdf_serializer == nil {
+		return nil, &SerializerError{Op: "create serializer", Syntax: serializer.syntax(), Err: ErrUnsupportedSyntax}
+	}
 
 	runtime.SetFinalizer(&serializer, (*Serializer).Free)
 
@@ -64,6 +105,10 @@ func (serializer *Serializer) SerializeModelToString(model *Model, baseUri *Uri)
 	var err error
 	var resultString string
 
+	if err = serializer.validate("serialize model", checkModel(model)); err != nil {
+		return "", err
+	}
+
 	var baseUriPtr *C.librdf_uri
 	baseUriPtr = nil
 
@@ -74,24 +119,299 @@ func (serializer *Serializer) SerializeModelToString(model *Model, baseUri *Uri)
 	result := C.librdf_serializer_serialize_model_to_string(serializer.librdf_serializer, baseUriPtr, model.librdf_model)
 
 	if result == nil {
-		err = errors.New("Unable to serialize model")
+		err = &SerializerError{Op: "serialize model", Syntax: serializer.syntax(), Err: ErrLibrdfFailed}
 	} else {
 		resultString = C.GoString((*C.char)(unsafe.Pointer(result)))
+		C.free(unsafe.Pointer(result))
 	}
 
 	return resultString, err
 }
 
+//SetNamespace declares a namespace prefix to be used in the serialized output
+//	An empty prefix declares the default namespace
+func (serializer *Serializer) SetNamespace(prefix string, uri *Uri) error {
+	op := fmt.Sprintf("set namespace prefix %q", prefix)
+	if err := serializer.validate(op, checkUri(uri)); err != nil {
+		return err
+	}
+
+	var cPrefix *C.char
+	if prefix != "" {
+		cPrefix = C.CString(prefix)
+		defer C.free(unsafe.Pointer(cPrefix))
+	}
+
+	if retCode := C.librdf_serializer_set_namespace(serializer.librdf_serializer, uri.librdf_uri, cPrefix); retCode != 0 {
+		return &SerializerError{Op: op, Syntax: serializer.syntax(), Err: ErrLibrdfFailed}
+	}
+
+	// namespaces are also kept for serializers created by SerializeStreamToWriter
+	serializer.namespaces = appendNamespace(serializer.namespaces, Namespace{Prefix: prefix, Uri: uri.ToString()})
+
+	return nil
+}
+
+//CopyNamespaces declares each namespace seen by a parser on the serializer
+//	so that output keeps the prefixes used in the parsed data
+func (serializer *Serializer) CopyNamespaces(parser *Parser) error {
+	if err := serializer.validate("copy namespaces"); err != nil {
+		return err
+	}
+
+	for _, namespace := range parser.NamespacesSeen() {
+		uri, err := NewUri(serializer.world, namespace.Uri)
+		if err != nil {
+			return err
+		}
+
+		err = serializer.SetNamespace(namespace.Prefix, uri)
+		uri.Free()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//SerializeModelToWriter serializes a model to w in the format appropriate for the serializer
+//	Output is written to w as it is produced rather than being built in memory
+func (serializer *Serializer) SerializeModelToWriter(w io.Writer, baseUri *Uri, model *Model) error {
+	if err := serializer.validate("serialize model", checkWriter(w), checkModel(model)); err != nil {
+		return err
+	}
+
+	var baseUriPtr *C.librdf_uri
+	if baseUri != nil {
+		baseUriPtr = baseUri.librdf_uri
+	}
+
+	return serializer.world.writeToIostream(w, func(iostream *C.raptor_iostream) error {
+		if retCode := C.librdf_serializer_serialize_model_to_iostream(serializer.librdf_serializer, baseUriPtr, model.librdf_model, iostream); retCode != 0 {
+			return &SerializerError{Op: "serialize model", Syntax: serializer.syntax(), Err: ErrLibrdfFailed}
+		}
+		return nil
+	})
+}
+
+//SerializeModelToFile serializes a model to the named file in the format appropriate for the serializer
+//	The file is created, or truncated if it already exists
+func (serializer *Serializer) SerializeModelToFile(path string, baseUri *Uri, model *Model) error {
+	if err := serializer.validate("serialize model", checkModel(model)); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+
+	err = serializer.SerializeModelToWriter(writer, baseUri, model)
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+//SerializeStreamToWriter serializes the statements read from an iterator to w in the format appropriate for the serializer
+//	Each statement is freed once it has been written, so statements may be passed straight from iterators such as
+//	Parser.ParseAsStream or Model.Statements.  Iteration stops at the first error returned by the iterator.
+func (serializer *Serializer) SerializeStreamToWriter(w io.Writer, baseUri *Uri, statements iter.Seq2[*Statement, error]) error {
+	if err := serializer.validate("serialize statements", checkWriter(w)); err != nil {
+		return err
+	}
+
+	raptorSerializer, err := serializer.newRaptorSerializer()
+	if err != nil {
+		return err
+	}
+	defer C.raptor_free_serializer(raptorSerializer)
+
+	var baseUriPtr *C.raptor_uri
+	if baseUri != nil {
+		baseUriPtr = baseUri.librdf_uri
+	}
+
+	for _, namespace := range serializer.namespaces {
+		if err = setRaptorSerializerNamespace(serializer.world, raptorSerializer, namespace); err != nil {
+			return err
+		}
+	}
+
+	return serializer.world.writeToIostream(w, func(iostream *C.raptor_iostream) error {
+		if retCode := C.raptor_serializer_start_to_iostream(raptorSerializer, baseUriPtr, iostream); retCode != 0 {
+			return &SerializerError{Op: "start serialization", Syntax: serializer.syntax(), Err: ErrLibrdfFailed}
+		}
+
+		for statement, err := range statements {
+			if err != nil {
+				return err
+			}
+
+			if checkStatement(statement) != nil {
+				return &SerializerError{Op: "serialize statement", Syntax: serializer.syntax(), Err: ErrNilArgument}
+			}
+
+			retCode := C.raptor_serializer_serialize_statement(raptorSerializer, statement.librdf_statement)
+			statement.Free()
+
+			if retCode != 0 {
+				return &SerializerError{Op: "serialize statement", Syntax: serializer.syntax(), Err: ErrLibrdfFailed}
+			}
+		}
+
+		if retCode := C.raptor_serializer_serialize_end(raptorSerializer); retCode != 0 {
+			return &SerializerError{Op: "end serialization", Syntax: serializer.syntax(), Err: ErrLibrdfFailed}
+		}
+		return nil
+	})
+}
+
+//newRaptorSerializer constructs a raptor serializer for the syntax of the serializer
+//	When no name is given the syntax is chosen from the mime type, defaulting to RDF/XML as librdf does
+func (serializer *Serializer) newRaptorSerializer() (*C.raptor_serializer, error) {
+	raptorWorld := serializer.world.GetRaptorWorld()
+	if raptorWorld == nil {
+		return nil, &SerializerError{Op: "create serializer", Syntax: serializer.syntax(), Err: ErrWorldClosed}
+	}
+
+	name := serializer.name
+	if name == "" {
+		name = "rdfxml"
+		if serializer.mimeType != "" {
+			if name = raptorSerializerNameForMimeType(raptorWorld, serializer.mimeType); name == "" {
+				return nil, &SerializerError{Op: "create serializer", Syntax: serializer.mimeType, Err: ErrUnsupportedSyntax}
+			}
+		}
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	raptorSerializer := C.raptor_new_serializer(raptorWorld, cName)
+	if raptorSerializer == nil {
+		return nil, &SerializerError{Op: "create serializer", Syntax: name, Err: ErrUnsupportedSyntax}
+	}
+
+	return raptorSerializer, nil
+}
+
+//setRaptorSerializerNamespace declares a namespace prefix on a raptor serializer
+func setRaptorSerializerNamespace(world *World, raptorSerializer *C.raptor_serializer, namespace Namespace) error {
+	uri, err := NewUri(world, namespace.Uri)
+	if err != nil {
+		return err
+	}
+	defer uri.Free()
+
+	var cPrefix *C.char
+	if namespace.Prefix != "" {
+		cPrefix = C.CString(namespace.Prefix)
+		defer C.free(unsafe.Pointer(cPrefix))
+	}
+
+	if retCode := C.raptor_serializer_set_namespace(raptorSerializer, uri.librdf_uri, (*C.uchar)(unsafe.Pointer(cPrefix))); retCode != 0 {
+		return &SerializerError{Op: fmt.Sprintf("set namespace prefix %q", namespace.Prefix), Err: ErrLibrdfFailed}
+	}
+
+	return nil
+}
+
+//raptorSerializerNameForMimeType returns the name of the first raptor serializer that supports a mime type, or "" if there is none
+func raptorSerializerNameForMimeType(raptorWorld *C.raptor_world, mimeType string) string {
+	for counter := C.uint(0); ; counter++ {
+		description := C.raptor_world_get_serializer_description(raptorWorld, counter)
+		if description == nil {
+			return ""
+		}
+
+		mimeTypes := unsafe.Slice(description.mime_types, description.mime_types_count)
+		for _, typeQ := range mimeTypes {
+			if C.GoString(typeQ.mime_type) == mimeType {
+				return C.GoString(*description.names)
+			}
+		}
+	}
+}
+
+//writeToIostream calls write with a raptor iostream that writes to w
+//	The iostream is freed once write returns.  An error returned by w takes precedence over the error returned by write.
+func (world *World) writeToIostream(w io.Writer, write func(iostream *C.raptor_iostream) error) error {
+	writer := &iostreamWriter{w: w}
+
+	handle := cgo.NewHandle(writer)
+	defer handle.Delete()
+
+	raptorWorld := world.GetRaptorWorld()
+	if raptorWorld == nil {
+		return &WorldError{Op: "create iostream", Err: ErrWorldClosed}
+	}
+
+	iostream := C.golibrdf_new_writer_iostream(raptorWorld, C.uintptr_t(handle))
+	if iostream == nil {
+		return &WorldError{Op: "create iostream", Err: ErrNullResult}
+	}
+
+	err := write(iostream)
+	C.raptor_free_iostream(iostream)
+
+	if writer.err != nil {
+		return writer.err
+	}
+
+	return err
+}
+
 //Free cleans up memory resources held by the Serializer
 //	Free will be automatically called when Serializer instances are garbage collected
 //  however it is important to explicitly call Free to avoid issues that may result
 //  from freeing resources in an unexpected order
 func (serializer *Serializer) Free() {
 
-	if serializer.librdf_serializer != nil {
-		serializer.librdf_serializer = nil
+	if serializer != nil && serializer.librdf_serializer != nil {
 		C.librdf_free_serializer(serializer.librdf_serializer)
+		serializer.librdf_serializer = nil
 	}
 
 	return
 }
+
+//validate returns a *SerializerError for op if the serializer has been freed or any of the argument checks failed
+func (serializer *Serializer) validate(op string, argumentErrors ...error) error {
+	if serializer == nil || serializer.librdf_serializer == nil {
+		return &SerializerError{Op: op, Err: ErrFreed}
+	}
+
+	for _, err := range argumentErrors {
+		if err != nil {
+			return &SerializerError{Op: op, Syntax: serializer.syntax(), Err: err}
+		}
+	}
+
+	return nil
+}
+
+//syntax returns the name of the serializer, or its mime type if no name was given
+func (serializer *Serializer) syntax() string {
+	if serializer.name == "" {
+		return serializer.mimeType
+	}
+	return serializer.name
+}
+
+//checkWriter returns ErrNilArgument if w is nil
+func checkWriter(w io.Writer) error {
+	if w == nil {
+		return ErrNilArgument
+	}
+	return nil
+}
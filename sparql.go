@@ -0,0 +1,245 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//sparqlTemplatePart is a piece of a SPARQL query template, either literal query text or a variable reference
+//	For a variable reference text holds the variable as written, including its ? or $ prefix.
+//	isBindable is false for variables that name results rather than match them, such as those in a SELECT
+//	projection, a GROUP BY or ORDER BY clause or following AS, which must remain variables for the query to be valid
+type sparqlTemplatePart struct {
+	text       string
+	variable   string
+	isBindable bool
+}
+
+//parseSparqlTemplate splits a SPARQL query into query text and variable references
+//	Strings, IRIs and comments are skipped so that text such as "?x" or <urn:?x> inside them is not taken as a variable
+func parseSparqlTemplate(template string) ([]sparqlTemplatePart, error) {
+	var parts []sparqlTemplatePart
+
+	// inClause is true after a keyword that introduces result variables, until the next group or WHERE,
+	// and isAfterAs is true between AS and the variable it assigns
+	inClause := false
+	isAfterAs := false
+
+	start := 0
+	for i := 0; i < len(template); {
+		switch c := template[i]; c {
+		case '{', '}':
+			inClause = false
+			i++
+		case '#':
+			end := strings.IndexAny(template[i:], "\r\n")
+			if end < 0 {
+				end = len(template) - i
+			}
+			i += end
+		case '"', '\'':
+			length, err := sparqlStringLength(template[i:])
+			if err != nil {
+				return nil, err
+			}
+			i += length
+		case '<':
+			// '<' is also the less than operator, so is only treated as an IRI if it forms a valid IRI reference
+			i += sparqlIriLength(template[i:])
+		case '?', '$':
+			length := sparqlVariableNameLength(template[i+1:])
+			if length == 0 {
+				i++
+				continue
+			}
+			if start < i {
+				parts = append(parts, sparqlTemplatePart{text: template[start:i]})
+			}
+			parts = append(parts, sparqlTemplatePart{text: template[i : i+1+length], variable: template[i+1 : i+1+length], isBindable: !inClause && !isAfterAs})
+			isAfterAs = false
+			i += 1 + length
+			start = i
+		default:
+			length := sparqlKeywordLength(template, i)
+			if length == 0 {
+				i++
+				continue
+			}
+			switch strings.ToUpper(template[i : i+length]) {
+			case "SELECT", "GROUP", "ORDER", "HAVING", "VALUES":
+				inClause = true
+			case "WHERE":
+				inClause = false
+			case "AS":
+				isAfterAs = true
+			}
+			i += length
+		}
+	}
+
+	if start < len(template) {
+		parts = append(parts, sparqlTemplatePart{text: template[start:]})
+	}
+
+	return parts, nil
+}
+
+//sparqlKeywordLength returns the length of the keyword starting at position i of text, or 0 if there is none
+//	Letters that are part of a prefixed name or language tag, or that follow other name characters, are not a keyword
+func sparqlKeywordLength(text string, i int) int {
+	isLetter := func(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+	if !isLetter(text[i]) || i > 0 && (isLetter(text[i-1]) || strings.IndexByte("0123456789_-.:@", text[i-1]) >= 0) {
+		return 0
+	}
+
+	length := 1
+	for i+length < len(text) && isLetter(text[i+length]) {
+		length++
+	}
+
+	if i+length < len(text) && strings.IndexByte("0123456789_-.:", text[i+length]) >= 0 {
+		return 0
+	}
+
+	return length
+}
+
+//sparqlStringLength returns the length of the quoted string, including its delimiters, at the start of text
+func sparqlStringLength(text string) (int, error) {
+	delimiter := text[:1]
+	if strings.HasPrefix(text, strings.Repeat(delimiter, 3)) {
+		delimiter = text[:3]
+	}
+
+	for i := len(delimiter); i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			i++
+		case strings.HasPrefix(text[i:], delimiter):
+			return i + len(delimiter), nil
+		case len(delimiter) == 1 && (text[i] == '\n' || text[i] == '\r'):
+			return 0, errors.New("unescaped line break in string")
+		}
+	}
+
+	return 0, errors.New("unterminated string")
+}
+
+//sparqlIriLength returns the length of the IRI reference at the start of text, or 1 if the '<' does not start an IRI reference
+func sparqlIriLength(text string) int {
+	for i := 1; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '>':
+			return i + 1
+		case c <= 0x20, strings.IndexByte("<\"{}|^`\\", c) >= 0:
+			return 1
+		}
+	}
+
+	return 1
+}
+
+//sparqlVariableNameLength returns the length of the variable name at the start of text
+func sparqlVariableNameLength(text string) int {
+	length := 0
+	for length < len(text) {
+		r, size := utf8.DecodeRuneInString(text[length:])
+		if !isSparqlVariableNameRune(r, length == 0) {
+			break
+		}
+		length += size
+	}
+
+	return length
+}
+
+//isSparqlVariableNameRune returns true if r may appear in a SPARQL variable name
+func isSparqlVariableNameRune(r rune, isFirst bool) bool {
+	switch {
+	case r == '_', unicode.IsLetter(r), unicode.IsDigit(r):
+		return true
+	case isFirst:
+		return false
+	}
+
+	return r == 0xB7 || unicode.Is(unicode.Mn, r) || r >= 0x203F && r <= 0x2040
+}
+
+//formatSparqlIri formats an IRI as a SPARQL IRI reference
+//	IRIs containing characters that cannot appear in an IRI reference are rejected rather than escaped,
+//	as SPARQL processes \u escapes before parsing and an escaped '>' would end the IRI
+func formatSparqlIri(iri string) (string, error) {
+	for _, r := range iri {
+		if r <= 0x20 || strings.ContainsRune("<>\"{}|^`\\", r) {
+			return "", fmt.Errorf("invalid character %q in IRI %q", r, iri)
+		}
+	}
+
+	return "<" + iri + ">", nil
+}
+
+//formatSparqlLiteral formats a literal in SPARQL syntax
+//	language and datatype may be empty, datatype is ignored when a language is given
+func formatSparqlLiteral(lexicalForm string, language string, datatype string) (string, error) {
+	var builder strings.Builder
+
+	builder.WriteString("\"")
+	for _, r := range lexicalForm {
+		switch r {
+		case '"':
+			builder.WriteString("\\\"")
+		case '\\':
+			builder.WriteString("\\\\")
+		case '\n':
+			builder.WriteString("\\n")
+		case '\r':
+			builder.WriteString("\\r")
+		case '\t':
+			builder.WriteString("\\t")
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteString("\"")
+
+	switch {
+	case language != "":
+		if _, _, rest, err := parseNTriplesLiteralSuffix("@" + language); err != nil || rest != "" {
+			return "", fmt.Errorf("invalid language tag %q", language)
+		}
+		builder.WriteString("@" + language)
+	case datatype != "":
+		datatypeIri, err := formatSparqlIri(datatype)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString("^^" + datatypeIri)
+	}
+
+	return builder.String(), nil
+}
@@ -30,7 +30,6 @@ package golibrdf
 import "C"
 
 import (
-	"errors"
 	"runtime"
 	"unsafe"
 )
@@ -58,21 +57,45 @@ type Statement struct {
 
 //NewStatementFromNodes constructs a statement given subject, predicate and object nodes
 func NewStatementFromNodes(world *World, subject *Node, predicate *Node, object *Node) (*Statement, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &StatementError{Op: "create statement from nodes", Err: err}
+	}
+
 	statement := Statement{}
 	statement.world = world
-	statement.librdf_statement = C.librdf_new_statement_from_nodes(world.librdf_world, subject.librdf_node, predicate.librdf_node, object.librdf_node)
+	statement.librdf_statement = C.librdf_new_statement_from_nodes(world.librdf_world, nodeOrNil(subject), nodeOrNil(predicate), nodeOrNil(object))
+
+	if statement.librdf_statement == nil {
+		return nil, &StatementError{Op: "create statement from nodes", Err: ErrNullResult}
+	}
 
 	runtime.SetFinalizer(&statement, (*Statement).Free)
 
 	return &statement, nil
 }
 
+//nodeOrNil returns the librdf node held by node, or nil for a nil node so that partial statements may be constructed
+func nodeOrNil(node *Node) *C.librdf_node {
+	if node == nil {
+		return nil
+	}
+	return node.librdf_node
+}
+
 //NewStatement constructs a new statement
 func NewStatement(world *World) (*Statement, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &StatementError{Op: "create statement", Err: err}
+	}
+
 	statement := Statement{}
 	statement.world = world
 	statement.librdf_statement = C.librdf_new_statement(world.librdf_world)
 
+	if statement.librdf_statement == nil {
+		return nil, &StatementError{Op: "create statement", Err: ErrNullResult}
+	}
+
 	runtime.SetFinalizer(&statement, (*Statement).Free)
 
 	return &statement, nil
@@ -80,10 +103,18 @@ func NewStatement(world *World) (*Statement, error) {
 
 //DeepClone performs a deep clone of a statement and returns a clone
 func (statement *Statement) DeepClone() (*Statement, error) {
+	if statement == nil || statement.librdf_statement == nil {
+		return nil, &StatementError{Op: "deep clone statement", Err: ErrFreed}
+	}
+
 	newStatement := Statement{}
 	newStatement.world = statement.world
 	newStatement.librdf_statement = C.librdf_new_statement_from_statement(statement.librdf_statement)
 
+	if newStatement.librdf_statement == nil {
+		return nil, &StatementError{Op: "deep clone statement", Err: ErrNullResult}
+	}
+
 	runtime.SetFinalizer(&newStatement, (*Statement).Free)
 
 	return &newStatement, nil
@@ -91,19 +122,28 @@ func (statement *Statement) DeepClone() (*Statement, error) {
 
 //ShallowClone performs a shallow clone of a statement and returns a clone
 func (statement *Statement) ShallowClone() (*Statement, error) {
+	if statement == nil || statement.librdf_statement == nil {
+		return nil, &StatementError{Op: "shallow clone statement", Err: ErrFreed}
+	}
+
 	newStatement := Statement{}
 	newStatement.world = statement.world
 	newStatement.librdf_statement = C.librdf_new_statement_from_statement2(statement.librdf_statement)
 
+	if newStatement.librdf_statement == nil {
+		return nil, &StatementError{Op: "shallow clone statement", Err: ErrNullResult}
+	}
+
 	runtime.SetFinalizer(&newStatement, (*Statement).Free)
 
 	return &newStatement, nil
 }
 
 //Clear removes the nodes associated with a statement
+//	Clear does nothing if the statement is nil or has already been freed
 func (statement *Statement) Clear() {
-	if statement.librdf_statement == nil {
-		panic(errors.New("Statement can't be cleared as it has already been freed"))
+	if statement == nil || statement.librdf_statement == nil {
+		return
 	}
 	C.librdf_statement_clear(statement.librdf_statement)
 
@@ -115,7 +155,7 @@ func (statement *Statement) Clear() {
 //  however it is important to explicitly call Free to avoid issues that may result
 //  from freeing resources in an unexpected order
 func (statement *Statement) Free() {
-	if statement.librdf_statement != nil {
+	if statement != nil && statement.librdf_statement != nil {
 		C.librdf_free_statement(statement.librdf_statement)
 		statement.librdf_statement = nil
 	}
@@ -207,14 +247,14 @@ func (statement *Statement) Encode() (string, error) {
 		sizeNeeded := C.librdf_statement_encode2(statement.world.librdf_world, statement.librdf_statement, nil, 0)
 
 		if sizeNeeded == 0 {
-			err = errors.New("Unable to determine size of buffer needed for encoding")
+			err = &StatementError{Op: "encode statement", Err: ErrLibrdfFailed}
 		} else {
 			bufferSize = sizeNeeded
 			buffer = make([]byte, bufferSize)
 			written := C.librdf_statement_encode2(statement.world.librdf_world, statement.librdf_statement, (*C.uchar)(unsafe.Pointer(&buffer[0])), bufferSize)
 
 			if written == 0 {
-				err = errors.New("Unable to encode, even after explicitly determining required buffer size")
+				err = &StatementError{Op: "encode statement", Err: ErrLibrdfFailed}
 			}
 		}
 	}
@@ -248,14 +288,14 @@ func (statement *Statement) EncodeParts(contextNode *Node, parts int) (string, e
 		sizeNeeded := C.librdf_statement_encode_parts2(statement.world.librdf_world, statement.librdf_statement, nodeRef, nil, 0, partsFields)
 
 		if sizeNeeded == 0 {
-			err = errors.New("Unable to determine size of buffer needed for encoding")
+			err = &StatementError{Op: "encode statement", Err: ErrLibrdfFailed}
 		} else {
 			bufferSize = sizeNeeded
 			buffer = make([]byte, bufferSize)
 			written := C.librdf_statement_encode_parts2(statement.world.librdf_world, statement.librdf_statement, nodeRef, (*C.uchar)(unsafe.Pointer(&buffer[0])), bufferSize, partsFields)
 
 			if written == 0 {
-				err = errors.New("Unable to encode, even after explicitly determining required buffer size")
+				err = &StatementError{Op: "encode statement", Err: ErrLibrdfFailed}
 			}
 		}
 	}
@@ -303,7 +343,7 @@ func (statement *Statement) decodeInner(world *World, encodedStatement string, w
 	}
 
 	if read == 0 {
-		err = errors.New("Unable to determine size of buffer needed for encoding")
+		err = &StatementError{Op: "decode statement", Err: ErrLibrdfFailed}
 	}
 
 	return node, err
@@ -313,17 +353,21 @@ func (statement *Statement) decodeInner(world *World, encodedStatement string, w
 func (statement *Statement) ToString() (string, error) {
 	var stringPointer unsafe.Pointer
 	var length C.size_t
+
+	if err := checkStatement(statement); err != nil {
+		return "", &StatementError{Op: "write statement", Err: err}
+	}
 	
 	raptorWorld := statement.world.GetRaptorWorld()
 	
 	stream := C.raptor_new_iostream_to_string(raptorWorld, &stringPointer, &length, nil)
 	if stream == nil {
-		return "", errors.New("Unable to obtain raptor stream")
+		return "", &StatementError{Op: "write statement", Err: ErrNullResult}
 	}
 	defer C.raptor_free_iostream(stream)
 
 	if result := C.librdf_statement_write(statement.librdf_statement, stream); result != 0 {
-		return "", errors.New("Unable to write statement")
+		return "", &StatementError{Op: "write statement", Err: ErrLibrdfFailed}
 	}
 	defer C.free(unsafe.Pointer(stringPointer))
 	
@@ -30,7 +30,6 @@ package golibrdf
 import "C"
 
 import (
-	"errors"
 	"runtime"
 	"unsafe"
 )
@@ -40,6 +39,9 @@ type Storage struct {
 }
 
 func NewStorage(world *World, storageName string, name string, options string) (*Storage, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &StorageError{Op: "make new storage", StorageName: storageName, Err: err}
+	}
 
 	cStorageName := C.CString(storageName)
 	defer C.free(unsafe.Pointer(cStorageName))
@@ -54,7 +56,7 @@ func NewStorage(world *World, storageName string, name string, options string) (
 	storage.librdf_storage = C.librdf_new_storage(world.librdf_world, cStorageName, cName, cOptions)
 
 	if storage.librdf_storage == nil {
-		return nil, errors.New("Unable to make new storage.  Call to librdf_storage failed.")
+		return nil, &StorageError{Op: "make new storage", StorageName: storageName, Err: ErrNullResult}
 	}
 
 	runtime.SetFinalizer(&storage, (*Storage).Free)
@@ -67,7 +69,7 @@ func NewStorage(world *World, storageName string, name string, options string) (
 //  however it is important to explicitly call Free to avoid issues that may result
 //  from freeing resources in an unexpected order
 func (storage *Storage) Free() {
-	if storage.librdf_storage != nil {
+	if storage != nil && storage.librdf_storage != nil {
 		C.librdf_free_storage(storage.librdf_storage)
 		storage.librdf_storage = nil
 	}
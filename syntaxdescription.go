@@ -0,0 +1,170 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information 
+* on the Redland libraries that this package wraps 
+*
+* This golibrdf package is: 
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+* 
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+*/
+
+package golibrdf
+
+// #cgo linux pkg-config: redland raptor2
+// #cgo LDFLAGS: -lrdf
+// #include <stdlib.h>
+// #include <string.h>
+// #include <strings.h>
+// #include <librdf.h>
+import "C"
+
+import (
+	"sort"
+	"unsafe"
+)
+
+//SyntaxDescription describes a syntax supported by a parser, serializer, query language or query results format
+//	Name is the name used to construct a Parser, Serializer or Query for the syntax and Aliases lists any other names
+//	that may be used.  MimeTypes are listed with the preferred mime type first.
+type SyntaxDescription struct {
+	Name      string
+	Aliases   []string
+	Label     string
+	MimeTypes []string
+	Uris      []string
+}
+
+//StorageDescription describes a storage module, such as "memory" or "hashes", that may be used to construct a Storage
+type StorageDescription struct {
+	Name  string
+	Label string
+}
+
+//Parsers returns descriptions of the syntaxes that may be parsed
+func (world *World) Parsers() ([]SyntaxDescription, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &WorldError{Op: "list parsers", Err: err}
+	}
+
+	raptorWorld := world.GetRaptorWorld()
+
+	return syntaxDescriptions(func(counter C.uint) *C.raptor_syntax_description {
+		return C.raptor_world_get_parser_description(raptorWorld, counter)
+	}), nil
+}
+
+//Serializers returns descriptions of the syntaxes that models may be serialized to
+func (world *World) Serializers() ([]SyntaxDescription, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &WorldError{Op: "list serializers", Err: err}
+	}
+
+	raptorWorld := world.GetRaptorWorld()
+
+	return syntaxDescriptions(func(counter C.uint) *C.raptor_syntax_description {
+		return C.raptor_world_get_serializer_description(raptorWorld, counter)
+	}), nil
+}
+
+//QueryLanguages returns descriptions of the languages that queries may be written in
+func (world *World) QueryLanguages() ([]SyntaxDescription, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &WorldError{Op: "list query languages", Err: err}
+	}
+
+	rasqalWorld := world.GetRasqalWorld()
+
+	return syntaxDescriptions(func(counter C.uint) *C.raptor_syntax_description {
+		return C.rasqal_world_get_query_language_description(rasqalWorld, counter)
+	}), nil
+}
+
+//QueryResultFormats returns descriptions of the formats that query results may be formatted in
+//	The names may be passed to Model.ExecuteQueryToFormattedString
+func (world *World) QueryResultFormats() ([]SyntaxDescription, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &WorldError{Op: "list query result formats", Err: err}
+	}
+
+	rasqalWorld := world.GetRasqalWorld()
+
+	return syntaxDescriptions(func(counter C.uint) *C.raptor_syntax_description {
+		return C.rasqal_world_get_query_results_format_description(rasqalWorld, counter)
+	}), nil
+}
+
+//Storages returns descriptions of the storage modules that may be used to construct a Storage
+func (world *World) Storages() ([]StorageDescription, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &WorldError{Op: "list storages", Err: err}
+	}
+
+	var descriptions []StorageDescription
+
+	for counter := C.uint(0); ; counter++ {
+		// the name and label are owned by librdf and must not be freed
+		var cName, cLabel *C.char
+		if C.librdf_storage_enumerate(world.librdf_world, counter, &cName, &cLabel) != 0 {
+			return descriptions, nil
+		}
+
+		descriptions = append(descriptions, StorageDescription{Name: C.GoString(cName), Label: C.GoString(cLabel)})
+	}
+}
+
+//syntaxDescriptions returns a copy of each description returned by describe, stopping at the first null description
+func syntaxDescriptions(describe func(counter C.uint) *C.raptor_syntax_description) []SyntaxDescription {
+	var descriptions []SyntaxDescription
+
+	for counter := C.uint(0); ; counter++ {
+		description := describe(counter)
+		if description == nil {
+			return descriptions
+		}
+
+		descriptions = append(descriptions, newSyntaxDescription(description))
+	}
+}
+
+//newSyntaxDescription copies a raptor syntax description
+func newSyntaxDescription(description *C.raptor_syntax_description) SyntaxDescription {
+	syntaxDescription := SyntaxDescription{Label: C.GoString(description.label)}
+
+	if description.names_count > 0 {
+		names := unsafe.Slice(description.names, description.names_count)
+
+		syntaxDescription.Name = C.GoString(names[0])
+		for _, name := range names[1:] {
+			syntaxDescription.Aliases = append(syntaxDescription.Aliases, C.GoString(name))
+		}
+	}
+
+	if description.mime_types_count > 0 {
+		// the mime types are ordered by their quality, highest first
+		mimeTypes := append([]C.raptor_type_q(nil), unsafe.Slice(description.mime_types, description.mime_types_count)...)
+		sort.SliceStable(mimeTypes, func(i, j int) bool { return mimeTypes[i].q > mimeTypes[j].q })
+
+		for _, typeQ := range mimeTypes {
+			syntaxDescription.MimeTypes = append(syntaxDescription.MimeTypes, C.GoString(typeQ.mime_type))
+		}
+	}
+
+	if description.uri_strings_count > 0 {
+		for _, uriString := range unsafe.Slice(description.uri_strings, description.uri_strings_count) {
+			syntaxDescription.Uris = append(syntaxDescription.Uris, C.GoString(uriString))
+		}
+	}
+
+	return syntaxDescription
+}
@@ -0,0 +1,340 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information
+* on the Redland libraries that this package wraps
+*
+* This golibrdf package is:
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+*
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+ */
+
+package golibrdf
+
+// #cgo linux pkg-config: redland raptor2
+// #cgo LDFLAGS: -lrdf
+// #include <stdlib.h>
+// #include <string.h>
+// #include <strings.h>
+// #include <librdf.h>
+import "C"
+
+//Tx is a transaction on a Model, created by Model.Begin
+//	Storages with native transaction support use librdf transactions.  For other storages the
+//	changes made through the Tx are applied immediately and recorded so that they can be undone by Rollback
+type Tx struct {
+	model    *Model
+	isNative bool
+	isDone   bool
+	undoLog  []txOperation
+}
+
+//txOperation records a change made by a transaction without native support so that it can be undone
+type txOperation struct {
+	wasAdded  bool
+	context   *Node
+	statement *Statement
+}
+
+//Begin starts a transaction on the model
+//	A corresponding Commit or Rollback call must be made to end the transaction
+func (model *Model) Begin() (*Tx, error) {
+	if err := model.validate("begin transaction"); err != nil {
+		return nil, err
+	}
+
+	tx := Tx{model: model}
+	tx.isNative = C.librdf_model_transaction_start(model.librdf_model) == 0
+
+	return &tx, nil
+}
+
+//IsNative returns true if the transaction is handled by the storage rather than recorded and undone by the Tx
+func (tx *Tx) IsNative() bool {
+	return tx.isNative
+}
+
+//AddStatement adds the specified statement to the model as part of the transaction
+func (tx *Tx) AddStatement(statement *Statement) error {
+	return tx.AddStatementWithContext(nil, statement)
+}
+
+//AddStatementWithContext adds the specified statement to the model within the given context as part of the transaction
+//	contextNode may be nil to add the statement without a context
+func (tx *Tx) AddStatementWithContext(contextNode *Node, statement *Statement) error {
+	if tx.isDone {
+		return &TxError{Op: "add statement", Err: ErrTxDone}
+	}
+
+	if err := tx.model.validate("add statement", checkStatement(statement)); err != nil {
+		return err
+	}
+
+	if tx.isNative {
+		return tx.model.addStatementWithOptionalContext(contextNode, statement)
+	}
+
+	if tx.model.containsStatementWithOptionalContext(contextNode, statement) {
+		return nil
+	}
+
+	if err := tx.model.addStatementWithOptionalContext(contextNode, statement); err != nil {
+		return err
+	}
+
+	return tx.record(true, contextNode, statement)
+}
+
+//RemoveStatement removes the specified statement from the model as part of the transaction
+func (tx *Tx) RemoveStatement(statement *Statement) error {
+	return tx.RemoveStatementWithContext(nil, statement)
+}
+
+//RemoveStatementWithContext removes the specified statement from the given context as part of the transaction
+//	contextNode may be nil to remove the statement without regard to context
+func (tx *Tx) RemoveStatementWithContext(contextNode *Node, statement *Statement) error {
+	if tx.isDone {
+		return &TxError{Op: "remove statement", Err: ErrTxDone}
+	}
+
+	if err := tx.model.validate("remove statement", checkStatement(statement)); err != nil {
+		return err
+	}
+
+	if tx.isNative {
+		return tx.model.removeStatementWithOptionalContext(contextNode, statement)
+	}
+
+	if !tx.model.containsStatementWithOptionalContext(contextNode, statement) {
+		return nil
+	}
+
+	if contextNode != nil {
+		if err := tx.model.RemoveStatementWithContext(contextNode, statement); err != nil {
+			return err
+		}
+		return tx.record(false, contextNode, statement)
+	}
+
+	// a statement removed without a context may have been held in several contexts, each of which
+	// must be recorded so that Rollback restores the statement to the contexts it was removed from
+	contextsBefore, err := tx.model.statementContexts(statement)
+	if err != nil {
+		return err
+	}
+	defer freeNodes(contextsBefore)
+
+	if err = tx.model.RemoveStatement(statement); err != nil {
+		return err
+	}
+
+	contextsAfter, err := tx.model.statementContexts(statement)
+	if err != nil {
+		return err
+	}
+	defer freeNodes(contextsAfter)
+
+	for _, context := range contextsBefore {
+		if containsNode(contextsAfter, context) {
+			continue
+		}
+
+		if err = tx.record(false, context, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Commit makes the changes made in the transaction permanent and ends the transaction
+func (tx *Tx) Commit() error {
+	if tx.isDone {
+		return &TxError{Op: "commit transaction", Err: ErrTxDone}
+	}
+	tx.isDone = true
+
+	if err := tx.model.validate("commit transaction"); err != nil {
+		tx.freeUndoLog()
+		return err
+	}
+
+	if tx.isNative {
+		if retCode := C.librdf_model_transaction_commit(tx.model.librdf_model); retCode != 0 {
+			return &TxError{Op: "commit transaction", Err: ErrLibrdfFailed}
+		}
+		return nil
+	}
+
+	tx.freeUndoLog()
+
+	return nil
+}
+
+//Rollback discards the changes made in the transaction and ends the transaction
+//	For transactions without native support each recorded change is undone in reverse order
+func (tx *Tx) Rollback() error {
+	if tx.isDone {
+		return &TxError{Op: "roll back transaction", Err: ErrTxDone}
+	}
+	tx.isDone = true
+
+	if err := tx.model.validate("roll back transaction"); err != nil {
+		tx.freeUndoLog()
+		return err
+	}
+
+	if tx.isNative {
+		if retCode := C.librdf_model_transaction_rollback(tx.model.librdf_model); retCode != 0 {
+			return &TxError{Op: "roll back transaction", Err: ErrLibrdfFailed}
+		}
+		return nil
+	}
+
+	var err error
+	for i := len(tx.undoLog) - 1; i >= 0; i-- {
+		var undoErr error
+		operation := tx.undoLog[i]
+
+		if operation.wasAdded {
+			undoErr = tx.model.removeStatementWithOptionalContext(operation.context, operation.statement)
+		} else {
+			undoErr = tx.model.addStatementWithOptionalContext(operation.context, operation.statement)
+		}
+
+		if undoErr != nil && err == nil {
+			err = &TxError{Op: "roll back transaction", Err: undoErr}
+		}
+	}
+
+	tx.freeUndoLog()
+
+	return err
+}
+
+//record adds a copy of a change to the undo log
+func (tx *Tx) record(wasAdded bool, contextNode *Node, statement *Statement) error {
+	operation := txOperation{wasAdded: wasAdded}
+
+	var err error
+	if operation.statement, err = statement.DeepClone(); err != nil {
+		return err
+	}
+
+	if contextNode != nil {
+		if operation.context, err = NewNode(tx.model.world); err != nil {
+			return err
+		}
+		operation.context.librdf_node = C.librdf_new_node_from_node(contextNode.librdf_node)
+	}
+
+	tx.undoLog = append(tx.undoLog, operation)
+
+	return nil
+}
+
+//freeUndoLog frees the copies held in the undo log
+func (tx *Tx) freeUndoLog() {
+	for _, operation := range tx.undoLog {
+		operation.statement.Free()
+		if operation.context != nil {
+			operation.context.Free()
+		}
+	}
+
+	tx.undoLog = nil
+}
+
+//addStatementWithOptionalContext adds a statement to the model, within the context if one is given
+func (model *Model) addStatementWithOptionalContext(contextNode *Node, statement *Statement) error {
+	if contextNode != nil {
+		return model.AddStatementWithContext(contextNode, statement)
+	}
+
+	return model.AddStatement(statement)
+}
+
+//removeStatementWithOptionalContext removes a statement from the model, from the context if one is given
+func (model *Model) removeStatementWithOptionalContext(contextNode *Node, statement *Statement) error {
+	if contextNode != nil {
+		return model.RemoveStatementWithContext(contextNode, statement)
+	}
+
+	return model.RemoveStatement(statement)
+}
+
+//containsStatementWithOptionalContext returns true if the model contains a statement, within the context if one is given
+func (model *Model) containsStatementWithOptionalContext(contextNode *Node, statement *Statement) bool {
+	if contextNode == nil {
+		return model.ContainsStatement(statement)
+	}
+
+	stream := C.librdf_model_find_statements_in_context(model.librdf_model, statement.librdf_statement, contextNode.librdf_node)
+	if stream == nil {
+		return false
+	}
+	defer C.librdf_free_stream(stream)
+
+	return C.librdf_stream_end(stream) == 0
+}
+
+//statementContexts returns a copy of the context of each statement in the model matching the given statement
+//	A statement held without a context is reported as a nil entry.  The caller owns the nodes returned.
+func (model *Model) statementContexts(statement *Statement) ([]*Node, error) {
+	stream := C.librdf_model_find_statements(model.librdf_model, statement.librdf_statement)
+	if stream == nil {
+		return nil, &ModelError{Op: "find statement contexts", Err: ErrNullResult}
+	}
+	defer C.librdf_free_stream(stream)
+
+	var contexts []*Node
+	for C.librdf_stream_end(stream) == 0 {
+		librdfContext := (*C.librdf_node)(C.librdf_stream_get_context2(stream))
+
+		if librdfContext == nil {
+			contexts = append(contexts, nil)
+		} else {
+			context, err := NewNode(model.world)
+			if err != nil {
+				freeNodes(contexts)
+				return nil, err
+			}
+			// the stream owns the context node so a copy is made for the caller
+			context.librdf_node = C.librdf_new_node_from_node(librdfContext)
+			contexts = append(contexts, context)
+		}
+
+		C.librdf_stream_next(stream)
+	}
+
+	return contexts, nil
+}
+
+//containsNode returns true if nodes holds a node equal to node, where nil matches a nil entry
+func containsNode(nodes []*Node, node *Node) bool {
+	for _, candidate := range nodes {
+		if candidate.Equals(node) {
+			return true
+		}
+	}
+	return false
+}
+
+//freeNodes frees each non-nil node in nodes
+func freeNodes(nodes []*Node) {
+	for _, node := range nodes {
+		if node != nil {
+			node.Free()
+		}
+	}
+}
@@ -30,7 +30,6 @@ package golibrdf
 import "C"
 
 import (
-	"errors"
 	"runtime"
 	"unsafe"
 )
@@ -53,6 +52,10 @@ func NewUri(world *World, uriString string) (*Uri, error) {
 
 //newUriWithoutFinaliser constructs a new URI given a string, but does not associate a finalizer for automatic free
 func newUriWithoutFinaliser(world *World, uriString string) (*Uri, error) {
+	if err := checkWorld(world); err != nil {
+		return nil, &UriError{Op: "create URI", Uri: uriString, Err: err}
+	}
+
 	uri := new(Uri)
 
 	cUriString := C.CString(uriString)
@@ -61,7 +64,7 @@ func newUriWithoutFinaliser(world *World, uriString string) (*Uri, error) {
 	uri.librdf_uri = C.librdf_new_uri(world.librdf_world, (*C.uchar)(unsafe.Pointer(cUriString)))
 
 	if uri.librdf_uri == nil {
-		return nil, errors.New("Unable to create URI for uri string")
+		return nil, &UriError{Op: "create URI", Uri: uriString, Err: ErrNullResult}
 	}
 
 	return uri, nil
@@ -72,7 +75,7 @@ func newUriWithoutFinaliser(world *World, uriString string) (*Uri, error) {
 //  however it is important to explicitly call Free to avoid issues that may result
 //  from freeing resources in an unexpected order
 func (uri *Uri) Free() {
-	if uri.librdf_uri != nil {
+	if uri != nil && uri.librdf_uri != nil {
 		C.librdf_free_uri(uri.librdf_uri)
 		uri.librdf_uri = nil
 	}
@@ -81,105 +84,144 @@ func (uri *Uri) Free() {
 }
 
 //ToString serializers a URI to string
+//	An empty string is returned if the URI has been freed
 func (uri Uri) ToString() string {
+	if uri.librdf_uri == nil {
+		return ""
+	}
+
+	// the string returned by librdf_uri_as_string is shared with the URI and must not be freed
 	cUriString := C.librdf_uri_as_string(uri.librdf_uri)
-	defer C.free(unsafe.Pointer(cUriString))
 
 	return C.GoString((*C.char)(unsafe.Pointer(cUriString)))
 }
 
 //NewUriFromUri constructs a new URI given an existing URI
 func NewUriFromUri(fromUri *Uri) (*Uri, error) {
-	var err error
+	if err := checkUri(fromUri); err != nil {
+		return nil, &UriError{Op: "copy URI", Err: err}
+	}
 
 	uri := new(Uri)
-	uri.librdf_uri = C.librdf_new_uri_from_uri(fromUri.librdf_uri)
+	if uri.librdf_uri = C.librdf_new_uri_from_uri(fromUri.librdf_uri); uri.librdf_uri == nil {
+		return nil, &UriError{Op: "copy URI", Uri: fromUri.ToString(), Err: ErrNullResult}
+	}
 
 	runtime.SetFinalizer(uri, (*Uri).Free)
 
-	return uri, err
+	return uri, nil
 }
 
 //NewUriFromUri constructs a new URI given an existing URI and a localName
 func NewUriFromUriLocalName(fromUri *Uri, localName string) (*Uri, error) {
-	var err error
+	if err := checkUri(fromUri); err != nil {
+		return nil, &UriError{Op: "create URI with local name", Uri: localName, Err: err}
+	}
 
 	cLocalName := C.CString(localName)
 	defer C.free(unsafe.Pointer(cLocalName))
 
 	uri := new(Uri)
-	uri.librdf_uri = C.librdf_new_uri_from_uri_local_name(fromUri.librdf_uri, (*C.uchar)(unsafe.Pointer(cLocalName)))
+	if uri.librdf_uri = C.librdf_new_uri_from_uri_local_name(fromUri.librdf_uri, (*C.uchar)(unsafe.Pointer(cLocalName))); uri.librdf_uri == nil {
+		return nil, &UriError{Op: "create URI with local name", Uri: localName, Err: ErrNullResult}
+	}
 
 	runtime.SetFinalizer(uri, (*Uri).Free)
 
-	return uri, err
+	return uri, nil
 }
 
 //NewUriFromUri constructs a new URI given an existing URI normalised to the specified baseUri
 func NewUriNormalisedBase(uriString string, sourceUri *Uri, baseUri *Uri) (*Uri, error) {
-	var err error
+	if checkUri(sourceUri) != nil || checkUri(baseUri) != nil {
+		return nil, &UriError{Op: "normalise URI", Uri: uriString, Err: ErrNilArgument}
+	}
 
 	cUriString := C.CString(uriString)
 	defer C.free(unsafe.Pointer(cUriString))
 
 	uri := new(Uri)
-	uri.librdf_uri = C.librdf_new_uri_normalised_to_base((*C.uchar)(unsafe.Pointer(cUriString)), sourceUri.librdf_uri, baseUri.librdf_uri)
+	if uri.librdf_uri = C.librdf_new_uri_normalised_to_base((*C.uchar)(unsafe.Pointer(cUriString)), sourceUri.librdf_uri, baseUri.librdf_uri); uri.librdf_uri == nil {
+		return nil, &UriError{Op: "normalise URI", Uri: uriString, Err: ErrNullResult}
+	}
 
 	runtime.SetFinalizer(uri, (*Uri).Free)
 
-	return uri, err
+	return uri, nil
 }
 
 //NewUriRelativeToBase constructs a new URI given a URI string made relative to the specified baseUri
 func NewUriRelativeToBase(baseUri *Uri, uriString string) (*Uri, error) {
-	var err error
+	if err := checkUri(baseUri); err != nil {
+		return nil, &UriError{Op: "resolve URI", Uri: uriString, Err: err}
+	}
 
 	cUriString := C.CString(uriString)
 	defer C.free(unsafe.Pointer(cUriString))
 
 	uri := new(Uri)
-	uri.librdf_uri = C.librdf_new_uri_relative_to_base(baseUri.librdf_uri, (*C.uchar)(unsafe.Pointer(cUriString)))
+	if uri.librdf_uri = C.librdf_new_uri_relative_to_base(baseUri.librdf_uri, (*C.uchar)(unsafe.Pointer(cUriString))); uri.librdf_uri == nil {
+		return nil, &UriError{Op: "resolve URI", Uri: uriString, Err: ErrNullResult}
+	}
 
 	runtime.SetFinalizer(uri, (*Uri).Free)
 
-	return uri, err
+	return uri, nil
 }
 
 //NewUriFromFileName constructs a new URI for a file given a filename
 func NewUriFromFileName(world *World, fileName string) (*Uri, error) {
-	var err error
+	if err := checkWorld(world); err != nil {
+		return nil, &UriError{Op: "create URI from file name", Uri: fileName, Err: err}
+	}
 
 	cFileName := C.CString(fileName)
 	defer C.free(unsafe.Pointer(cFileName))
 
 	uri := new(Uri)
-	uri.librdf_uri = C.librdf_new_uri_from_filename(world.librdf_world, (*C.char)(unsafe.Pointer(cFileName)))
+	if uri.librdf_uri = C.librdf_new_uri_from_filename(world.librdf_world, (*C.char)(unsafe.Pointer(cFileName))); uri.librdf_uri == nil {
+		return nil, &UriError{Op: "create URI from file name", Uri: fileName, Err: ErrNullResult}
+	}
 
 	runtime.SetFinalizer(uri, (*Uri).Free)
 
-	return uri, err
+	return uri, nil
 }
 
 //ToFileName converts a URI representing a file to a filename
 func (uri *Uri) ToFileName() (string, error) {
-	var err error
+	if checkUri(uri) != nil {
+		return "", &UriError{Op: "convert URI to file name", Err: ErrFreed}
+	}
 
 	cFileName := C.librdf_uri_to_filename(uri.librdf_uri)
+	if cFileName == nil {
+		return "", &UriError{Op: "convert URI to file name", Uri: uri.ToString(), Err: ErrNullResult}
+	}
 	defer C.free(unsafe.Pointer(cFileName))
 
 	fileName := C.GoString((*C.char)(unsafe.Pointer(cFileName)))
 
-	return fileName, err
+	return fileName, nil
 }
 
 //IsFileUri tests whether a URI represents a file or not.
 func (uri *Uri) IsFileUri() bool {
+	if checkUri(uri) != nil {
+		return false
+	}
+
 	cIsFileUri := int(C.librdf_uri_is_file_uri(uri.librdf_uri))
 	return cIsFileUri == 0
 }
 
 //Equals compares 2 URIs and returns true if they are equal
+//	A URI that has been freed is not equal to any other URI
 func (uri *Uri) Equals(other *Uri) bool {
+	if checkUri(uri) != nil || checkUri(other) != nil {
+		return false
+	}
+
 	cEquals := int(C.librdf_uri_equals(uri.librdf_uri, other.librdf_uri))
 	return cEquals == 0
 }
@@ -188,6 +230,16 @@ func (uri *Uri) Equals(other *Uri) bool {
 // Returns <0 if the URI instance is less than other
 // Returns >0 if the URI instance is greater than other
 // Returns 0 if the URIs are equal
+// A URI that has been freed is ordered before any other URI
 func (uri *Uri) Compare(other *Uri) int {
+	switch {
+	case checkUri(uri) != nil && checkUri(other) != nil:
+		return 0
+	case checkUri(uri) != nil:
+		return -1
+	case checkUri(other) != nil:
+		return 1
+	}
+
 	return int(C.librdf_uri_compare(uri.librdf_uri, other.librdf_uri))
 }
@@ -0,0 +1,628 @@
+/*
+*
+* This file forms part of the golibrdf package containing go language bindings,
+* tests and examples for the Redland RDF library.
+*
+* Please refer to http://librdf.org for copyright and licence information
+* on the Redland libraries that this package wraps
+*
+* This golibrdf package is:
+* 	Copyright (C) 2013, Phillip Pettit http://ppettit.net/
+*
+* This package is licensed under the following three licenses as alternatives:
+* 1. GNU Lesser General Public License (LGPL) V2.1 or any newer version
+* 2. GNU General Public License (GPL) V2 or any newer version
+* 3. Apache License, V2.0 or any newer version
+*
+* You may not use this file except in compliance with at least one of
+* the above three licenses.
+*
+ */
+
+package golibrdf
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//URIs of the XSD and RDF datatypes understood when converting literal nodes to and from Go values
+const (
+	XsdNamespace = "http://www.w3.org/2001/XMLSchema#"
+
+	XsdString             = XsdNamespace + "string"
+	XsdBoolean            = XsdNamespace + "boolean"
+	XsdDecimal            = XsdNamespace + "decimal"
+	XsdInteger            = XsdNamespace + "integer"
+	XsdNonPositiveInteger = XsdNamespace + "nonPositiveInteger"
+	XsdNegativeInteger    = XsdNamespace + "negativeInteger"
+	XsdNonNegativeInteger = XsdNamespace + "nonNegativeInteger"
+	XsdPositiveInteger    = XsdNamespace + "positiveInteger"
+	XsdLong               = XsdNamespace + "long"
+	XsdInt                = XsdNamespace + "int"
+	XsdShort              = XsdNamespace + "short"
+	XsdByte               = XsdNamespace + "byte"
+	XsdUnsignedLong       = XsdNamespace + "unsignedLong"
+	XsdUnsignedInt        = XsdNamespace + "unsignedInt"
+	XsdUnsignedShort      = XsdNamespace + "unsignedShort"
+	XsdUnsignedByte       = XsdNamespace + "unsignedByte"
+	XsdDouble             = XsdNamespace + "double"
+	XsdFloat              = XsdNamespace + "float"
+	XsdDateTime           = XsdNamespace + "dateTime"
+	XsdDateTimeStamp      = XsdNamespace + "dateTimeStamp"
+	XsdDate               = XsdNamespace + "date"
+	XsdTime               = XsdNamespace + "time"
+	XsdDuration           = XsdNamespace + "duration"
+	XsdDayTimeDuration    = XsdNamespace + "dayTimeDuration"
+	XsdAnyUri             = XsdNamespace + "anyURI"
+	XsdBase64Binary       = XsdNamespace + "base64Binary"
+	XsdHexBinary          = XsdNamespace + "hexBinary"
+
+	RdfNamespace  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	RdfLangString = RdfNamespace + "langString"
+	RdfType       = RdfNamespace + "type"
+	RdfFirst      = RdfNamespace + "first"
+	RdfRest       = RdfNamespace + "rest"
+	RdfNil        = RdfNamespace + "nil"
+)
+
+var (
+	decimalPattern  = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+	doublePattern   = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+	durationPattern = regexp.MustCompile(`^(-)?P(?:([0-9]+)Y)?(?:([0-9]+)M)?(?:([0-9]+)D)?(?:T(?:([0-9]+)H)?(?:([0-9]+)M)?(?:([0-9]+)(?:\.([0-9]+))?S)?)?$`)
+)
+
+//integer datatypes derived from xsd:integer with the bit size and sign constraints that apply to them
+var integerDatatypes = map[string]struct {
+	bitSize  int
+	unsigned bool
+	minSign  int
+	maxSign  int
+}{
+	XsdInteger:            {0, false, -1, 1},
+	XsdNonPositiveInteger: {0, false, -1, 0},
+	XsdNegativeInteger:    {0, false, -1, -1},
+	XsdNonNegativeInteger: {0, false, 0, 1},
+	XsdPositiveInteger:    {0, false, 1, 1},
+	XsdLong:               {64, false, -1, 1},
+	XsdInt:                {32, false, -1, 1},
+	XsdShort:              {16, false, -1, 1},
+	XsdByte:               {8, false, -1, 1},
+	XsdUnsignedLong:       {64, true, 0, 1},
+	XsdUnsignedInt:        {32, true, 0, 1},
+	XsdUnsignedShort:      {16, true, 0, 1},
+	XsdUnsignedByte:       {8, true, 0, 1},
+}
+
+//NewNodeFromValue constructs a new literal node from a Go value
+//	string values become plain literals, other values become typed literals:
+//	bool (xsd:boolean), signed and unsigned integers and *big.Int (xsd:integer),
+//	float64 (xsd:double), float32 (xsd:float), *big.Rat (xsd:decimal), time.Time (xsd:dateTime),
+//	time.Duration (xsd:duration), *url.URL (xsd:anyURI) and []byte (xsd:base64Binary)
+func NewNodeFromValue(world *World, value interface{}) (*Node, error) {
+	lexicalForm, datatype, err := formatLiteralValue(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if datatype == "" {
+		return NewNodeFromLiteral(world, lexicalForm)
+	}
+
+	return NewNodeFromTypedLiteralUriString(world, lexicalForm, datatype)
+}
+
+//Value converts a literal node to a Go value based on its datatype
+//	Plain, language tagged and xsd:string literals are returned as string.  Integer types are returned as
+//	int64 (uint64 for unsigned types, *big.Int where xsd:integer values exceed int64), xsd:decimal as *big.Rat,
+//	xsd:double as float64, xsd:float as float32, xsd:boolean as bool, xsd:dateTime, xsd:date and xsd:time as time.Time,
+//	xsd:duration as time.Duration, xsd:anyURI as *url.URL and binary types as []byte.
+//	Literals with other datatypes are returned as their lexical form.
+func (node *Node) Value() (interface{}, error) {
+	if !node.IsLiteral() {
+		return nil, errors.New("Unable to convert node to a value.  Node is not a literal.")
+	}
+
+	var datatype string
+	if datatypeUri := node.GetLiteralValueDatatypeUri(); datatypeUri != nil {
+		datatype = datatypeUri.ToString()
+		datatypeUri.Free()
+	}
+
+	return parseLiteralValue(node.GetLiteralValue(), datatype)
+}
+
+//categories of literal used to order literals, values are only compared within a category
+const (
+	literalCategoryNumeric = iota
+	literalCategoryBoolean
+	literalCategoryDateTime
+	literalCategoryString
+	literalCategoryOther
+)
+
+//ordering of floating point special values relative to finite numbers
+const (
+	numericClassNaN = iota
+	numericClassNegativeInfinity
+	numericClassFinite
+	numericClassPositiveInfinity
+)
+
+//literalTerm holds the parts of a literal node
+type literalTerm struct {
+	lexicalForm string
+	language    string
+	datatype    string
+}
+
+//numericOrderKey is used to order numeric literals, including floating point special values
+type numericOrderKey struct {
+	class int
+	value *big.Rat
+}
+
+//compareLiterals compares 2 literals, returning <0, 0 or >0 as first is less than, equal to or greater than second
+//	Literals are ordered by category and then by value within the category, ties are broken using the
+//	lexical form, language and datatype so that only identical literals compare as equal
+func compareLiterals(first literalTerm, second literalTerm) int {
+	firstCategory, firstValue := literalOrderKey(first)
+	secondCategory, secondValue := literalOrderKey(second)
+
+	if firstCategory != secondCategory {
+		return firstCategory - secondCategory
+	}
+
+	result := 0
+
+	switch firstCategory {
+	case literalCategoryNumeric:
+		firstNumber, secondNumber := firstValue.(numericOrderKey), secondValue.(numericOrderKey)
+		if result = firstNumber.class - secondNumber.class; result == 0 && firstNumber.class == numericClassFinite {
+			result = firstNumber.value.Cmp(secondNumber.value)
+		}
+	case literalCategoryBoolean:
+		if firstBool, secondBool := firstValue.(bool), secondValue.(bool); firstBool != secondBool {
+			if secondBool {
+				result = -1
+			} else {
+				result = 1
+			}
+		}
+	case literalCategoryDateTime:
+		result = firstValue.(time.Time).Compare(secondValue.(time.Time))
+	}
+
+	if result == 0 {
+		result = strings.Compare(first.lexicalForm, second.lexicalForm)
+	}
+	if result == 0 {
+		result = strings.Compare(first.language, second.language)
+	}
+	if result == 0 {
+		result = strings.Compare(first.datatype, second.datatype)
+	}
+
+	return result
+}
+
+//literalOrderKey returns the category of a literal and the value used to order it within the category
+func literalOrderKey(term literalTerm) (int, interface{}) {
+	if term.language != "" {
+		return literalCategoryString, nil
+	}
+
+	switch term.datatype {
+	case "", XsdString, RdfLangString:
+		return literalCategoryString, nil
+	}
+
+	value, err := parseLiteralValue(term.lexicalForm, term.datatype)
+	if err != nil {
+		return literalCategoryOther, nil
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return literalCategoryNumeric, numericOrderKey{numericClassFinite, new(big.Rat).SetInt64(v)}
+	case uint64:
+		return literalCategoryNumeric, numericOrderKey{numericClassFinite, new(big.Rat).SetUint64(v)}
+	case *big.Int:
+		return literalCategoryNumeric, numericOrderKey{numericClassFinite, new(big.Rat).SetInt(v)}
+	case *big.Rat:
+		return literalCategoryNumeric, numericOrderKey{numericClassFinite, v}
+	case float32:
+		return literalCategoryNumeric, floatOrderKey(float64(v))
+	case float64:
+		return literalCategoryNumeric, floatOrderKey(v)
+	case bool:
+		return literalCategoryBoolean, v
+	case time.Time:
+		if term.datatype == XsdDateTime || term.datatype == XsdDateTimeStamp {
+			return literalCategoryDateTime, v
+		}
+	}
+
+	return literalCategoryOther, nil
+}
+
+//floatOrderKey returns the numericOrderKey for a floating point value
+func floatOrderKey(value float64) numericOrderKey {
+	switch {
+	case math.IsNaN(value):
+		return numericOrderKey{class: numericClassNaN}
+	case math.IsInf(value, -1):
+		return numericOrderKey{class: numericClassNegativeInfinity}
+	case math.IsInf(value, 1):
+		return numericOrderKey{class: numericClassPositiveInfinity}
+	}
+
+	return numericOrderKey{numericClassFinite, new(big.Rat).SetFloat64(value)}
+}
+
+//parseLiteralValue converts the lexical form of a literal to a Go value based on the datatype URI
+func parseLiteralValue(lexicalForm string, datatype string) (interface{}, error) {
+	var value interface{}
+	var err error
+
+	switch datatype {
+	case "", XsdString, RdfLangString:
+		value = lexicalForm
+	case XsdBoolean:
+		value, err = parseBoolean(lexicalForm)
+	case XsdDecimal:
+		value, err = parseDecimal(lexicalForm)
+	case XsdDouble:
+		value, err = parseDouble(lexicalForm, 64)
+	case XsdFloat:
+		var floatValue float64
+		if floatValue, err = parseDouble(lexicalForm, 32); err == nil {
+			value = float32(floatValue)
+		}
+	case XsdDateTime:
+		value, err = parseTime(lexicalForm, "2006-01-02T15:04:05.999999999", true)
+	case XsdDateTimeStamp:
+		value, err = parseTime(lexicalForm, "2006-01-02T15:04:05.999999999", false)
+	case XsdDate:
+		value, err = parseTime(lexicalForm, "2006-01-02", true)
+	case XsdTime:
+		value, err = parseTime(lexicalForm, "15:04:05.999999999", true)
+	case XsdDuration, XsdDayTimeDuration:
+		value, err = parseDuration(lexicalForm)
+	case XsdAnyUri:
+		value, err = url.Parse(strings.TrimSpace(lexicalForm))
+	case XsdBase64Binary:
+		value, err = base64.StdEncoding.DecodeString(strings.TrimSpace(lexicalForm))
+	case XsdHexBinary:
+		value, err = hex.DecodeString(strings.TrimSpace(lexicalForm))
+	default:
+		if _, isInteger := integerDatatypes[datatype]; isInteger {
+			value, err = parseInteger(lexicalForm, datatype)
+		} else {
+			value = lexicalForm
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid lexical form %q for datatype <%s>: %w", lexicalForm, datatype, err)
+	}
+
+	return value, nil
+}
+
+//formatLiteralValue converts a Go value to a lexical form and datatype URI
+//	An empty datatype indicates a plain literal
+func formatLiteralValue(value interface{}) (string, string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, "", nil
+	case bool:
+		return strconv.FormatBool(v), XsdBoolean, nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), XsdInteger, nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), XsdInteger, nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), XsdInteger, nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), XsdInteger, nil
+	case int64:
+		return strconv.FormatInt(v, 10), XsdInteger, nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), XsdInteger, nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), XsdInteger, nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), XsdInteger, nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), XsdInteger, nil
+	case uint64:
+		return strconv.FormatUint(v, 10), XsdInteger, nil
+	case *big.Int:
+		if v == nil {
+			break
+		}
+		return v.String(), XsdInteger, nil
+	case float32:
+		return formatDouble(float64(v), 32), XsdFloat, nil
+	case float64:
+		return formatDouble(v, 64), XsdDouble, nil
+	case *big.Rat:
+		if v == nil {
+			break
+		}
+		lexicalForm, err := formatDecimal(v)
+		return lexicalForm, XsdDecimal, err
+	case time.Time:
+		return v.Format(time.RFC3339Nano), XsdDateTime, nil
+	case time.Duration:
+		return formatDuration(v), XsdDuration, nil
+	case *url.URL:
+		if v == nil {
+			break
+		}
+		return v.String(), XsdAnyUri, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), XsdBase64Binary, nil
+	}
+
+	return "", "", fmt.Errorf("Unable to convert value of type %T to a literal", value)
+}
+
+//parseBoolean parses the lexical form of an xsd:boolean
+func parseBoolean(lexicalForm string) (bool, error) {
+	switch strings.TrimSpace(lexicalForm) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	}
+
+	return false, errors.New("expected one of true, false, 1 or 0")
+}
+
+//parseInteger parses the lexical form of xsd:integer or one of its derived types
+func parseInteger(lexicalForm string, datatype string) (interface{}, error) {
+	constraints := integerDatatypes[datatype]
+
+	integer, ok := new(big.Int).SetString(strings.TrimSpace(lexicalForm), 10)
+	if !ok {
+		return nil, errors.New("not a valid integer")
+	}
+
+	if sign := integer.Sign(); sign < constraints.minSign || sign > constraints.maxSign {
+		return nil, errors.New("integer is outside the value space of the datatype")
+	}
+
+	if constraints.unsigned {
+		if !integer.IsUint64() || integer.BitLen() > constraints.bitSize {
+			return nil, errors.New("integer is out of range")
+		}
+		return integer.Uint64(), nil
+	}
+
+	if !integer.IsInt64() {
+		if constraints.bitSize == 0 {
+			return integer, nil
+		}
+		return nil, errors.New("integer is out of range")
+	}
+
+	value := integer.Int64()
+	if constraints.bitSize != 0 && constraints.bitSize < 64 {
+		limit := int64(1) << uint(constraints.bitSize-1)
+		if value < -limit || value >= limit {
+			return nil, errors.New("integer is out of range")
+		}
+	}
+
+	return value, nil
+}
+
+//parseDecimal parses the lexical form of an xsd:decimal
+func parseDecimal(lexicalForm string) (*big.Rat, error) {
+	lexicalForm = strings.TrimSpace(lexicalForm)
+
+	if !decimalPattern.MatchString(lexicalForm) {
+		return nil, errors.New("not a valid decimal")
+	}
+
+	decimal, ok := new(big.Rat).SetString(lexicalForm)
+	if !ok {
+		return nil, errors.New("not a valid decimal")
+	}
+
+	return decimal, nil
+}
+
+//formatDecimal formats a rational as an xsd:decimal, failing if it has no finite decimal representation
+func formatDecimal(decimal *big.Rat) (string, error) {
+	denominator := new(big.Int).Set(decimal.Denom())
+	remainder := new(big.Int)
+	two, five := big.NewInt(2), big.NewInt(5)
+
+	twos, fives := 0, 0
+	for remainder.Mod(denominator, two).Sign() == 0 {
+		denominator.Div(denominator, two)
+		twos++
+	}
+	for remainder.Mod(denominator, five).Sign() == 0 {
+		denominator.Div(denominator, five)
+		fives++
+	}
+
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("Unable to convert %s to a decimal literal.  Value has no finite decimal representation.", decimal.String())
+	}
+
+	digits := twos
+	if fives > digits {
+		digits = fives
+	}
+
+	if digits == 0 {
+		return decimal.FloatString(1), nil
+	}
+
+	return decimal.FloatString(digits), nil
+}
+
+//parseDouble parses the lexical form of an xsd:double or xsd:float
+func parseDouble(lexicalForm string, bitSize int) (float64, error) {
+	lexicalForm = strings.TrimSpace(lexicalForm)
+
+	switch lexicalForm {
+	case "INF", "+INF":
+		return math.Inf(1), nil
+	case "-INF":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+
+	if !doublePattern.MatchString(lexicalForm) {
+		return 0, errors.New("not a valid floating point number")
+	}
+
+	value, err := strconv.ParseFloat(lexicalForm, bitSize)
+	if err != nil {
+		return 0, errors.New("floating point number is out of range")
+	}
+
+	return value, nil
+}
+
+//formatDouble formats a floating point number as an xsd:double or xsd:float
+func formatDouble(value float64, bitSize int) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "INF"
+	case math.IsInf(value, -1):
+		return "-INF"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, bitSize)
+}
+
+//parseTime parses the lexical form of a date or time given a layout without a timezone
+//	The timezone is required unless optionalZone is true, in which case values without one are taken as UTC
+func parseTime(lexicalForm string, layout string, optionalZone bool) (time.Time, error) {
+	lexicalForm = strings.TrimSpace(lexicalForm)
+
+	if value, err := time.Parse(layout+"Z07:00", lexicalForm); err == nil {
+		return value, nil
+	}
+
+	if optionalZone {
+		if value, err := time.Parse(layout, lexicalForm); err == nil {
+			return value, nil
+		}
+	}
+
+	return time.Time{}, errors.New("not a valid date or time")
+}
+
+//parseDuration parses the lexical form of an xsd:duration
+//	Durations with year or month components have no fixed length and can't be represented as a time.Duration
+func parseDuration(lexicalForm string) (time.Duration, error) {
+	lexicalForm = strings.TrimSpace(lexicalForm)
+
+	parts := durationPattern.FindStringSubmatch(lexicalForm)
+	if parts == nil || strings.HasSuffix(lexicalForm, "P") || strings.HasSuffix(lexicalForm, "T") {
+		return 0, errors.New("not a valid duration")
+	}
+
+	if strings.Trim(parts[2]+parts[3], "0") != "" {
+		return 0, errors.New("durations with year or month components can't be represented as a time.Duration")
+	}
+
+	units := []struct {
+		part int
+		unit time.Duration
+	}{{4, 24 * time.Hour}, {5, time.Hour}, {6, time.Minute}, {7, time.Second}}
+
+	var duration time.Duration
+	for _, u := range units {
+		if parts[u.part] == "" {
+			continue
+		}
+		count, err := strconv.ParseInt(parts[u.part], 10, 64)
+		if err != nil || count > int64(math.MaxInt64/u.unit) || duration+time.Duration(count)*u.unit < duration {
+			return 0, errors.New("duration is out of range")
+		}
+		duration += time.Duration(count) * u.unit
+	}
+
+	if fraction := parts[8]; fraction != "" {
+		if len(fraction) > 9 {
+			fraction = fraction[:9]
+		}
+		nanoseconds, _ := strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64)
+		duration += time.Duration(nanoseconds)
+	}
+
+	if parts[1] == "-" {
+		duration = -duration
+	}
+
+	return duration, nil
+}
+
+//formatDuration formats a time.Duration as an xsd:duration using day, hour, minute and second components
+func formatDuration(duration time.Duration) string {
+	if duration == 0 {
+		return "PT0S"
+	}
+
+	var builder strings.Builder
+
+	magnitude := uint64(duration)
+	if duration < 0 {
+		builder.WriteString("-")
+		magnitude = uint64(-duration)
+	}
+	builder.WriteString("P")
+
+	day := uint64(24 * time.Hour)
+	if days := magnitude / day; days > 0 {
+		builder.WriteString(strconv.FormatUint(days, 10) + "D")
+		magnitude %= day
+	}
+
+	if magnitude == 0 {
+		return builder.String()
+	}
+	builder.WriteString("T")
+
+	if hours := magnitude / uint64(time.Hour); hours > 0 {
+		builder.WriteString(strconv.FormatUint(hours, 10) + "H")
+		magnitude %= uint64(time.Hour)
+	}
+
+	if minutes := magnitude / uint64(time.Minute); minutes > 0 {
+		builder.WriteString(strconv.FormatUint(minutes, 10) + "M")
+		magnitude %= uint64(time.Minute)
+	}
+
+	if magnitude > 0 {
+		seconds := strconv.FormatUint(magnitude/uint64(time.Second), 10)
+		if nanoseconds := magnitude % uint64(time.Second); nanoseconds > 0 {
+			seconds += strings.TrimRight(fmt.Sprintf(".%09d", nanoseconds), "0")
+		}
+		builder.WriteString(seconds + "S")
+	}
+
+	return builder.String()
+}
@@ -30,8 +30,8 @@ package golibrdf
 import "C"
 
 import (
-	"errors"
 	"runtime"
+	"runtime/cgo"
 	"unsafe"
 )
 
@@ -41,6 +41,8 @@ type World struct {
 	librdf_raptor_world *C.raptor_world
 	isOpen              bool
 	hasBeenOpen         bool
+	log                 *worldLog
+	logHandle           cgo.Handle
 }
 
 //NewWorld constructs a new World.  The World must be opened before use.
@@ -53,14 +55,17 @@ func NewWorld() *World {
 //Open readies a World for use.  A corresponding Close call must be made to free resources.
 func (world *World) Open() error {
 	if world.IsOpen() {
-		return errors.New("Unable to Open() world.  World is already open.")
+		return &WorldError{Op: "open world", Err: ErrWorldAlreadyOpen}
 	}
 
 	if world.hasBeenOpen {
-		return errors.New("Unable to Open() world.  World has previously been opened.")
+		return &WorldError{Op: "open world", Err: ErrWorldReopened}
 	}
 
-	world.librdf_world = C.librdf_new_world()
+	if world.librdf_world = C.librdf_new_world(); world.librdf_world == nil {
+		return &WorldError{Op: "open world", Err: ErrNullResult}
+	}
+	world.installLogger()
 	C.librdf_world_open(world.librdf_world)
 
 	world.isOpen = true
@@ -77,43 +82,101 @@ func (world World) IsOpen() bool {
 	return world.isOpen
 }
 
-//GetRaptorWorld returns a raptor reference associated with the world
+//GetRaptorWorld returns a raptor reference associated with the world, or nil if the world is not open
 func (world *World) GetRaptorWorld() *C.raptor_world {
+	if checkWorld(world) != nil {
+		return nil
+	}
 	return C.librdf_world_get_raptor(world.librdf_world)
 }
 
 //SetRaptorWorld associates a raptor world reference with the world
-func (world *World) SetRaptorWorld(raptorWorld *C.raptor_world) {
+func (world *World) SetRaptorWorld(raptorWorld *C.raptor_world) error {
+	if err := checkWorld(world); err != nil {
+		return &WorldError{Op: "set raptor world", Err: err}
+	}
+
 	C.librdf_world_set_raptor(world.librdf_world, raptorWorld)
+	return nil
 }
 
-//GetRasqalWorld returns a rasqal reference associated with the world
+//GetRasqalWorld returns a rasqal reference associated with the world, or nil if the world is not open
 func (world *World) GetRasqalWorld() *C.rasqal_world {
+	if checkWorld(world) != nil {
+		return nil
+	}
 	return C.librdf_world_get_rasqal(world.librdf_world)
 }
 
 //GuessParserName is used to guess the appropriate parser given a URI
+//	An empty string is returned if no parser could be guessed
 func (world *World) GuessParserName(uri *Uri) string {
-	var cUriAsString *C.uchar
-	if cUriAsString = C.librdf_uri_as_string(uri.librdf_uri); cUriAsString == nil {
+	if checkUri(uri) != nil {
 		return ""
 	}
-	defer C.free(unsafe.Pointer(cUriAsString))
 
-	var cParserName *C.char
-	if cParserName = C.librdf_parser_guess_name2(world.librdf_world, nil, nil, cUriAsString); cParserName == nil {
+	parserName, err := world.GuessParser("", nil, uri)
+	if err != nil {
 		return ""
 	}
-	defer C.free(unsafe.Pointer(cParserName))
-
-	parserName := C.GoString(cParserName)
 
 	return parserName
 }
 
+//GuessParser guesses the name of the parser for RDF data from its mime type, content and URI
+//	Any of mimeType, content and uri may be empty or nil.  Raptor scores each parser on the mime type,
+//	the file extension of the URI and the start of the content, so the content alone is often enough to
+//	choose a parser for data with no reliable mime type or extension.  As with raptor's own guessing
+//	parser, only the first chunk of content is examined.
+func (world *World) GuessParser(mimeType string, content []byte, uri *Uri) (string, error) {
+	raptorWorld := world.GetRaptorWorld()
+	if raptorWorld == nil {
+		return "", &ParserError{Op: "guess parser", Syntax: mimeType, Err: ErrWorldClosed}
+	}
+
+	var cMimeType *C.char
+	if mimeType != "" {
+		cMimeType = C.CString(mimeType)
+		defer C.free(unsafe.Pointer(cMimeType))
+	}
+
+	if len(content) > parseChunkSize {
+		content = content[:parseChunkSize]
+	}
+
+	// the content is copied with a terminating NUL as some raptor recognisers treat it as a string
+	var cContent *C.uchar
+	if len(content) > 0 {
+		buffer := make([]byte, len(content)+1)
+		copy(buffer, content)
+
+		cContent = (*C.uchar)(C.CBytes(buffer))
+		defer C.free(unsafe.Pointer(cContent))
+	}
+
+	// the string returned by librdf_uri_as_string is shared with the URI and must not be freed
+	var cIdentifier *C.uchar
+	if checkUri(uri) == nil {
+		cIdentifier = C.librdf_uri_as_string(uri.librdf_uri)
+	}
+
+	// raptor owns the returned name, which must not be freed
+	cParserName := C.raptor_world_guess_parser_name(raptorWorld, nil, cMimeType, cContent, C.size_t(len(content)), cIdentifier)
+	if cParserName == nil {
+		return "", &ParserError{Op: "guess parser", Syntax: mimeType, Err: ErrUnsupportedSyntax}
+	}
+
+	return C.GoString(cParserName), nil
+}
+
 //SetRasqalWorld associates a rasqal world reference with the world
-func (world *World) SetRasqalWorld(rasqalWorld *C.rasqal_world) {
+func (world *World) SetRasqalWorld(rasqalWorld *C.rasqal_world) error {
+	if err := checkWorld(world); err != nil {
+		return &WorldError{Op: "set rasqal world", Err: err}
+	}
+
 	C.librdf_world_set_rasqal(world.librdf_world, rasqalWorld)
+	return nil
 }
 
 //Close cleans up memory resources held by the World
@@ -122,36 +185,61 @@ func (world *World) Close() {
 		C.librdf_free_world(world.librdf_world)
 		world.librdf_world = nil
 	}
+	world.removeLogger()
 
 	world.isOpen = false
 }
 
 //SetFeature specifies a value for a world feature (setting)
-func (world *World) SetFeature(feature *Uri, value *Node) {
-	C.librdf_world_set_feature(world.librdf_world, feature.librdf_uri, value.librdf_node)
+func (world *World) SetFeature(feature *Uri, value *Node) error {
+	if err := checkWorld(world); err != nil {
+		return &WorldError{Op: "set feature", Err: err}
+	}
+
+	if checkUri(feature) != nil || checkNodes(value) != nil {
+		return &WorldError{Op: "set feature", Err: ErrNilArgument}
+	}
+
+	if retCode := C.librdf_world_set_feature(world.librdf_world, feature.librdf_uri, value.librdf_node); retCode != 0 {
+		return &WorldError{Op: "set feature", Err: ErrLibrdfFailed}
+	}
+	return nil
 }
 
-//GetFeature returns a value node for a world feature
+//GetFeature returns a value node for a world feature, or nil if the feature has no value
 func (world *World) GetFeature(feature *Uri) (*Node, error) {
-	var node *Node
-	var err error
-	nodeValue := C.librdf_world_get_feature(world.librdf_world, feature.librdf_uri)
+	if err := checkWorld(world); err != nil {
+		return nil, &WorldError{Op: "get feature", Err: err}
+	}
+
+	if err := checkUri(feature); err != nil {
+		return nil, &WorldError{Op: "get feature", Err: err}
+	}
 
-	if nodeValue != nil {
-		node, err = NewNode(world)
+	nodeValue := C.librdf_world_get_feature(world.librdf_world, feature.librdf_uri)
+	if nodeValue == nil {
+		return nil, nil
+	}
 
-		if err != nil {
-			node.librdf_node = nodeValue
-		}
+	node, err := NewNode(world)
+	if err != nil {
+		C.librdf_free_node(nodeValue)
+		return nil, err
 	}
+	node.librdf_node = nodeValue
 
-	return node, err
+	return node, nil
 }
 
 //SetDigest sets a digest for the world
-func (world *World) SetDigest(name string) {
+func (world *World) SetDigest(name string) error {
+	if err := checkWorld(world); err != nil {
+		return &WorldError{Op: "set digest", Err: err}
+	}
+
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 
 	C.librdf_world_set_digest(world.librdf_world, cName)
+	return nil
 }